@@ -3,33 +3,77 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bestk/zeeho-widgets/backend"
+	"github.com/bestk/zeeho-widgets/capture"
+	"github.com/bestk/zeeho-widgets/internal/fsutil"
+	"github.com/bestk/zeeho-widgets/secrets"
+	"github.com/fsnotify/fsnotify"
 	"github.com/go-co-op/gocron"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx       context.Context
-	config    *Config
-	scheduler *gocron.Scheduler
+	ctx        context.Context
+	store      *Store
+	scheduler  *gocron.Scheduler
+	jobMu      sync.Mutex
+	refreshJob *gocron.Job
+	refreshMu  sync.Mutex // 定时刷新与单个账号的手动刷新不同时请求接口
+	refresh    *refreshState
+	guard      *apiGuard
+	captureMu  sync.Mutex
+	capture    *capture.Proxy
+	captureTo  string      // 抓包获取的 Token 保存到的账号
+	captureEnd *time.Timer // 抓包超时后自动停止代理
+	captureGen uint64      // 每次开始或停止抓包时加一，过期的超时计时器据此忽略
+	secrets    *secrets.Store
+	configMu   sync.Mutex
+	watcher    *fsnotify.Watcher
+	configHash [32]byte // 最近一次写入配置文件的内容摘要
+	configErr  error    // 配置文件无法解析，例如由更新的版本写入，此时不覆盖该文件
+	overrides  configOverrides
+	scheduled  refreshSettings // 当前定时任务使用的配置
+	desktop    backend.DesktopIntegration
+	desktopMu  sync.Mutex
+	// desktopApplied 已经应用到窗口的透明度、鼠标穿透和快捷键
+	desktopApplied desktopSettings
+	clickPaused    bool // 通过快捷键临时关闭了鼠标穿透
+	stopHotkey     func()
+	apiBase        string // 接口地址，默认为 apiBaseURL，测试时指向本地服务
+	tray           *tray
+	instance       net.Listener // 单实例锁，接收其它实例转发的操作
+	windowHidden   atomic.Bool  // 窗口已隐藏到托盘
 }
 
 // Config represents the application configuration
 type Config struct {
-	Token          string `json:"token"`
-	VehicleID      string `json:"vehicleId"`
-	UpdateInterval int    `json:"updateInterval"`
+	Version        int          `json:"version"` // 配置文件格式版本，见 configVersion
+	Profiles       []Profile    `json:"profiles"`
+	ActiveProfile  string       `json:"activeProfile"` // 当前展示的账号，为空时合并展示全部账号
+	UpdateInterval int          `json:"updateInterval"`
+	Window         WindowConfig `json:"window"`
 }
 
+// apiBaseURL Zeeho 接口地址
+const apiBaseURL = "https://tapi.zeehoev.com/v1.0/app/cfmotoserverapp"
+
+// 未配置时使用的 acw_tc Cookie
+const defaultAcwTC = "0b32824217388280172008957ec68b4a84c95e1b5efd8b103d6c69b40480d9"
+
 // VehicleData represents the vehicle information
 type VehicleData struct {
 	VinNo                      string        `json:"vinNo"`
@@ -106,6 +150,11 @@ type VehicleData struct {
 	VehicleScalePicUrl         string        `json:"vehicleScalePicUrl"`
 	GaodeLincenseVinNo         string        `json:"gaodeLincenseVinNo"`
 	GaodeLincenseId            string        `json:"gaodeLincenseId"`
+
+	// StaleSince 本地字段，接口不可用时展示的是缓存数据，值为数据的更新时间
+	StaleSince string `json:"staleSince,omitempty"`
+	// Profile 本地字段，车辆所属的账号
+	Profile string `json:"profile,omitempty"`
 }
 
 type EncryptInfo struct {
@@ -158,18 +207,92 @@ type APIResponse struct {
 }
 
 // NewApp creates a new App application struct
-func NewApp() *App {
+func NewApp(overrides configOverrides) *App {
 	app := &App{
 		scheduler: gocron.NewScheduler(time.UTC),
-	}
+		refresh:   newRefreshState(),
+		store:     NewStore(),
+		guard:     newAPIGuard(),
+		overrides: overrides,
+		desktop:   backend.New(),
+		apiBase:   apiBaseURL,
+	}
+	app.migrateLegacyPaths()
+	app.secrets = secrets.New(app.getSecretsPath())
+	app.guard.onChange = app.onBreakerChange
 	app.loadConfig()
+	app.loadCache()
+	app.store.Subscribe(app.onStoreChange)
 	return app
 }
 
+// onStoreChange 配置变更后通知前端并重新安排定时任务
+func (a *App) onStoreChange(change StoreChange) {
+	if change != ConfigChanged {
+		return
+	}
+
+	// 与 GetConfig 一致，前端只拿到配置文件中的值
+	data, err := json.MarshalIndent(a.store.Config(), "", "  ")
+	if err == nil {
+		a.emit("configUpdate", string(data))
+	}
+
+	config := a.effectiveConfig()
+
+	// 窗口位置等变化不影响刷新，不需要重新安排定时任务
+	a.jobMu.Lock()
+	changed := !reflect.DeepEqual(a.scheduled, config.refreshSettings())
+	a.jobMu.Unlock()
+	if changed {
+		a.ScheduleRefresh()
+	}
+
+	a.applyDesktopSettings(false)
+}
+
+// emit 通知前端，窗口创建之前（以及测试中）没有 ctx 时忽略
+func (a *App) emit(event string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, event, data...)
+}
+
+// onBreakerChange 熔断器状态变化时通知前端
+func (a *App) onBreakerChange(status BreakerStatus) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, "breakerState", status)
+}
+
+// GetBreakerStatus 获取接口熔断器状态
+func (a *App) GetBreakerStatus() BreakerStatus {
+	return a.guard.Status()
+}
+
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	if err := a.watchConfig(); err != nil {
+		log.Printf("Watch config failed, external edits need a restart: %v", err)
+	}
+}
+
+// beforeClose 退出前保存窗口状态，返回 true 会阻止退出
+func (a *App) beforeClose(ctx context.Context) bool {
+	a.saveWindowState()
+	return false
+}
+
+// shutdown is called when the app is closing
+func (a *App) shutdown(ctx context.Context) {
+	a.stopWatchConfig()
+	a.stopDesktopSettings()
+	a.stopTray()
+	a.stopInstance()
 }
 
 // Greet returns a greeting for the given name
@@ -179,12 +302,21 @@ func (a *App) Greet(name string) string {
 
 // GetVehicleData fetches vehicle data from the API
 func (a *App) GetVehicleData() (*VehicleData, error) {
+	// 使用第一个配置了车架号的账号
+	var config Profile
+	for _, p := range a.effectiveConfig().activeProfiles() {
+		if p.VehicleID != "" {
+			config = p
+			break
+		}
+	}
+
 	// 检查配置是否存在
-	if a.config.Token == "" || a.config.VehicleID == "" {
+	if config.Token == "" || config.VehicleID == "" {
 		return nil, fmt.Errorf("请先配置Token和车架号")
 	}
 
-	url := fmt.Sprintf("https://tapi.zeehoev.com/v1.0/app/cfmotoserverapp/vehicle/widgets/%s", a.config.VehicleID)
+	url := fmt.Sprintf("%s/vehicle/widgets/%s", a.apiBase, config.VehicleID)
 
 	client := &http.Client{
 		Timeout: 10 * time.Second,
@@ -195,14 +327,14 @@ func (a *App) GetVehicleData() (*VehicleData, error) {
 		return nil, fmt.Errorf("创建请求失败: %v", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+a.config.Token)
+	req.Header.Set("Authorization", "Bearer "+config.Token)
 	req.Header.Set("User-Agent", "Apifox/1.0.0 (https://apifox.com)")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Cookie", "acw_tc=0b32824217388280172008957ec68b4a84c95e1b5efd8b103d6c69b40480d9")
+	req.Header.Set("Cookie", config.cookieHeader())
 
-	resp, err := client.Do(req)
+	resp, err := a.guard.Do(client, req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %v", err)
 	}
@@ -213,9 +345,9 @@ func (a *App) GetVehicleData() (*VehicleData, error) {
 		return nil, fmt.Errorf("读取响应失败: %v", err)
 	}
 
-	// 检查是否返回HTML（错误页面）
-	if len(body) > 0 && body[0] == '<' {
-		return nil, fmt.Errorf("认证失败，请检查Token是否正确")
+	// 检查是否认证失败（返回HTML错误页面或401/403）
+	if isAuthFailure(resp, body) {
+		return nil, errAuthFailed
 	}
 
 	// 检查非200状态码
@@ -262,9 +394,71 @@ func (a *App) GetVehicleData() (*VehicleData, error) {
 	return &data, nil
 }
 
-// VehicleHomePage 获取车辆首页数据
+// VehicleHomePage 获取车辆首页数据，合并当前账号中选择展示的车辆
 func (a *App) VehicleHomePage() (*[]VehicleData, error) {
-	url := "https://tapi.zeehoev.com/v1.0/app/cfmotoserverapp/vehicleHomePage"
+	profiles := a.effectiveConfig().activeProfiles()
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("请先配置Token")
+	}
+
+	data, failures := a.fetchProfiles(profiles)
+	if len(data) == 0 && len(failures) > 0 {
+		return nil, joinFailures(failures)
+	}
+
+	a.resolveAddresses(data)
+	return &data, nil
+}
+
+// resolveAddresses 获取每个车辆的地址信息
+func (a *App) resolveAddresses(data []VehicleData) {
+	for i := range data {
+		if data[i].Location.Longitude != 0 && data[i].Location.Latitude != 0 {
+			address, err := a.getAddressFromLocation(data[i].Location.Longitude, data[i].Location.Latitude)
+			if err == nil {
+				data[i].Location.Address = address
+			}
+		}
+	}
+}
+
+// fetchProfiles 依次请求每个账号的车辆数据，返回合并后的车辆和失败的账号
+func (a *App) fetchProfiles(profiles []Profile) ([]VehicleData, map[string]error) {
+	data := []VehicleData{}
+	failures := make(map[string]error)
+
+	for _, profile := range profiles {
+		vehicles, err := a.fetchHomePage(profile)
+		if err != nil {
+			failures[profile.Name] = err
+			continue
+		}
+
+		vehicles = profile.filterVehicles(vehicles)
+		for i := range vehicles {
+			vehicles[i].Profile = profile.Name
+		}
+		data = append(data, vehicles...)
+	}
+
+	return data, failures
+}
+
+// joinFailures 合并多个账号的错误，保留 errAuthFailed 以便判断
+func joinFailures(failures map[string]error) error {
+	errs := make([]error, 0, len(failures))
+	for name, err := range failures {
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+	if len(errs) == 1 {
+		return errors.Unwrap(errs[0])
+	}
+	return errors.Join(errs...)
+}
+
+// fetchHomePage 请求首页接口，返回账号下绑定的全部车辆
+func (a *App) fetchHomePage(config Profile) ([]VehicleData, error) {
+	url := a.apiBase + "/vehicleHomePage"
 
 	client := &http.Client{
 		Timeout: 10 * time.Second,
@@ -275,14 +469,14 @@ func (a *App) VehicleHomePage() (*[]VehicleData, error) {
 		return nil, fmt.Errorf("创建请求失败: %v", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+a.config.Token)
+	req.Header.Set("Authorization", "Bearer "+config.Token)
 	req.Header.Set("User-Agent", "Apifox/1.0.0 (https://apifox.com)")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Cookie", "acw_tc=0b32824217388280172008957ec68b4a84c95e1b5efd8b103d6c69b40480d9")
+	req.Header.Set("Cookie", config.cookieHeader())
 
-	resp, err := client.Do(req)
+	resp, err := a.guard.Do(client, req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %v", err)
 	}
@@ -293,11 +487,21 @@ func (a *App) VehicleHomePage() (*[]VehicleData, error) {
 		return nil, fmt.Errorf("读取响应失败: %v", err)
 	}
 
+	// 检查是否认证失败（返回HTML错误页面或401/403）
+	if isAuthFailure(resp, body) {
+		return nil, errAuthFailed
+	}
+
 	var apiResponse APIResponse
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
 		return nil, fmt.Errorf("解析JSON失败: %v", err)
 	}
 
+	// 检查API返回的状态码
+	if apiResponse.Code != "10000" {
+		return nil, fmt.Errorf("API返回错误: %s", apiResponse.Message)
+	}
+
 	// 将 interface{} 转换为 []interface{}
 	dataSlice, ok := apiResponse.Data.([]interface{})
 	if !ok {
@@ -316,17 +520,7 @@ func (a *App) VehicleHomePage() (*[]VehicleData, error) {
 		return nil, fmt.Errorf("数据解析失败: %v", err)
 	}
 
-	// 获取每个车辆的地址信息
-	for i := range data {
-		if data[i].Location.Longitude != 0 && data[i].Location.Latitude != 0 {
-			address, err := a.getAddressFromLocation(data[i].Location.Longitude, data[i].Location.Latitude)
-			if err == nil {
-				data[i].Location.Address = address
-			}
-		}
-	}
-
-	return &data, nil
+	return data, nil
 }
 
 // getAddressFromLocation 根据经纬度获取地址信息
@@ -362,8 +556,10 @@ func (a *App) getAddressFromLocation(longitude, latitude float64) (string, error
 
 // 配置文件路径
 func (a *App) getConfigPath() string {
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, ".zeeho-config.json")
+	if path := a.overrides.configPath(); path != "" {
+		return path
+	}
+	return filepath.Join(getConfigDir(), "config.json")
 }
 
 // 加载配置
@@ -371,56 +567,142 @@ func (a *App) loadConfig() {
 	configPath := a.getConfigPath()
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		a.config = &Config{}
+		a.store.SetConfig(Config{})
 		return
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		a.config = &Config{}
+	config, version, err := parseConfig(data)
+	if err != nil {
+		log.Printf("Load config failed, the file will not be overwritten: %v", err)
+		a.configMu.Lock()
+		a.configErr = err
+		a.configMu.Unlock()
+		a.store.SetConfig(Config{})
 		return
 	}
+	if err := config.validate(); err != nil {
+		// 仍然使用手动编辑后不合法的配置，保存时会要求修正
+		log.Printf("Config %s: %v", configPath, err)
+	}
 
-	a.config = &config
+	// 从旧版本升级时保留一份去掉 Token 的原文件，并按新格式重写
+	migrated := version < configVersion
+	if migrated {
+		backupPath := fmt.Sprintf("%s.v%d.bak", configPath, version)
+		backup, err := withoutTokens(data)
+		if err == nil {
+			err = fsutil.WriteFileAtomic(backupPath, backup, 0600)
+		}
+		if err != nil {
+			log.Printf("Backup config before migration failed: %v", err)
+		}
+	}
+
+	a.loadTokens(&config, migrated)
+	a.store.SetConfig(config)
 }
 
-// 保存配置
-func (a *App) saveConfig() error {
-	configPath := a.getConfigPath()
-	data, err := json.MarshalIndent(a.config, "", "  ")
-	if err != nil {
+// 保存配置，新增或修改的 Token 写入密钥环，配置文件中只保存引用。写入成功后再更新状态中心
+func (a *App) saveConfig(config *Config) error {
+	saved := a.store.Config()
+	for i := range config.Profiles {
+		profile := &config.Profiles[i]
+		if profile.Token == "" {
+			continue
+		}
+		profile.TokenMissing = false
+		// Token 没有变化时沿用已有的保存位置，不重复写入密钥环
+		if p, ok := saved.profile(profile.Name); ok && p.Token == profile.Token {
+			profile.TokenRef = p.TokenRef
+			continue
+		}
+		// 系统密钥环不可用且没有设置密码时返回 secrets.ErrNoBackend，由界面要求输入加密密码
+		ref, err := a.secrets.Save(profile.Name, profile.Token)
+		if err != nil {
+			return err
+		}
+		profile.TokenRef = ref
+	}
+
+	if err := a.writeConfigFile(config); err != nil {
 		return err
 	}
 
-	runtime.EventsEmit(a.ctx, "configUpdate", string(data))
+	a.store.SetConfig(*config)
+	return nil
+}
+
+// writeConfigFile 将配置写入文件，仅当前用户可读写。
+// 已保存到密钥环的 Token 不会写入文件
+func (a *App) writeConfigFile(config *Config) error {
+	fileConfig := config.clone()
+	for i := range fileConfig.Profiles {
+		if fileConfig.Profiles[i].TokenRef != "" {
+			fileConfig.Profiles[i].Token = ""
+		}
+	}
+
+	fileConfig.Version = configVersion
 
-	return os.WriteFile(configPath, data, 0644)
+	a.configMu.Lock()
+	loadErr := a.configErr
+	a.configMu.Unlock()
+	if loadErr != nil {
+		return fmt.Errorf("配置文件无法读取，为避免覆盖不会保存，请修正或删除 %s: %v", a.getConfigPath(), loadErr)
+	}
+
+	data, err := json.MarshalIndent(fileConfig, "", "  ")
+	if err != nil {
+		return err
+	}
+	a.rememberConfig(data)
+	return fsutil.WriteFileAtomic(a.getConfigPath(), data, 0600)
 }
 
-// GetConfig 获取当前配置
+// GetConfig 获取配置文件中的配置，不包含命令行参数和环境变量覆盖的值，
+// 避免配置界面保存时把覆盖的值写入配置文件
 func (a *App) GetConfig() *Config {
-	return a.config
+	config := a.store.Config()
+	return &config
 }
 
-// ValidateAndSaveConfig 验证并保存配置
-func (a *App) ValidateAndSaveConfig(token, vehicleId string, updateInterval int) error {
+// ValidateAndSaveConfig 验证并保存账号配置，profileName 为空时保存到默认账号
+func (a *App) ValidateAndSaveConfig(profileName, token, vehicleId string, updateInterval int, cookie string, selectedVehicles []string) error {
+	name, err := normalizeProfileName(profileName)
+	if err != nil {
+		return err
+	}
+
 	// 创建临时配置进行验证
-	tempConfig := &Config{
-		Token:          token,
-		VehicleID:      vehicleId,
-		UpdateInterval: updateInterval,
+	tempProfile := &Profile{
+		Name:             name,
+		Token:            token,
+		VehicleID:        vehicleId,
+		Cookie:           cookie,
+		SelectedVehicles: selectedVehicles,
+	}
+
+	config := a.store.Config()
+	config.upsertProfile(*tempProfile)
+	config.UpdateInterval = updateInterval
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	// 通过首页接口验证 Token，并确认选择的车辆都绑定在该账号下
+	if err := a.validateVehicles(tempProfile); err != nil {
+		return fmt.Errorf("配置验证失败: %v", err)
 	}
 
 	if vehicleId != "" {
 		// 验证配置是否有效
-		if err := a.validateConfig(tempConfig); err != nil {
+		if err := a.validateConfig(tempProfile); err != nil {
 			return fmt.Errorf("配置验证失败: %v", err)
 		}
 	}
 
 	// 验证成功，保存配置
-	a.config = tempConfig
-	if err := a.saveConfig(); err != nil {
+	if err := a.saveConfig(&config); err != nil {
 		return fmt.Errorf("保存配置失败: %v", err)
 	}
 
@@ -428,7 +710,7 @@ func (a *App) ValidateAndSaveConfig(token, vehicleId string, updateInterval int)
 }
 
 // 验证配置
-func (a *App) validateConfig(config *Config) error {
+func (a *App) validateConfig(config *Profile) error {
 	if config.Token == "" {
 		return fmt.Errorf("Token不能为空")
 	}
@@ -438,7 +720,7 @@ func (a *App) validateConfig(config *Config) error {
 	}
 
 	// 尝试调用API验证
-	url := fmt.Sprintf("https://tapi.zeehoev.com/v1.0/app/cfmotoserverapp/vehicle/widgets/%s", config.VehicleID)
+	url := fmt.Sprintf("%s/vehicle/widgets/%s", a.apiBase, config.VehicleID)
 
 	client := &http.Client{
 		Timeout: 10 * time.Second,
@@ -455,9 +737,9 @@ func (a *App) validateConfig(config *Config) error {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Cookie", "acw_tc=0b32824217388280172008957ec68b4a84c95e1b5efd8b103d6c69b40480d9")
+	req.Header.Set("Cookie", config.cookieHeader())
 
-	resp, err := client.Do(req)
+	resp, err := a.guard.Do(client, req)
 	if err != nil {
 		return fmt.Errorf("验证请求失败: %v", err)
 	}
@@ -468,16 +750,16 @@ func (a *App) validateConfig(config *Config) error {
 		return fmt.Errorf("读取验证响应失败: %v", err)
 	}
 
+	// 检查是否认证失败（返回HTML错误页面或401/403）
+	if isAuthFailure(resp, body) {
+		return errAuthFailed
+	}
+
 	// 检查响应状态
 	if resp.StatusCode != 200 {
 		return fmt.Errorf("API返回错误状态码 %d，请检查Token和车架号是否正确", resp.StatusCode)
 	}
 
-	// 检查是否返回HTML（认证失败）
-	if len(body) > 0 && body[0] == '<' {
-		return fmt.Errorf("认证失败，请检查Token是否正确")
-	}
-
 	// 尝试解析JSON
 	var apiResponse APIResponse
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
@@ -497,82 +779,69 @@ func (a *App) SetWindowPosition(x, y int) {
 	runtime.WindowSetPosition(a.ctx, x, y)
 }
 
-// MoveToCorner 移动窗口到指定角落
-func (a *App) MoveToCorner(corner string) {
-	// 获取屏幕尺寸
-	screen, err := runtime.ScreenGetAll(a.ctx)
-	if err != nil || len(screen) == 0 {
-		return
-	}
-
-	screenWidth := screen[0].Size.Width
-	screenHeight := screen[0].Size.Height
-
-	// 窗口尺寸
-	windowWidth := 440
-	windowHeight := 300
-
-	var x, y int
-
-	switch corner {
-	case "top-left":
-		x, y = 20, 20
-	case "top-right":
-		x, y = screenWidth-windowWidth-20, 20
-	case "bottom-left":
-		x, y = 20, screenHeight-windowHeight-60
-	case "bottom-right":
-		x, y = screenWidth-windowWidth-20, screenHeight-windowHeight-60
-	default:
-		// 默认右上角
-		x, y = screenWidth-windowWidth-20, 20
-	}
-
-	runtime.WindowSetPosition(a.ctx, x, y)
-}
-
 // MinimizeToTray 最小化到系统托盘
 func (a *App) MinimizeToTray() {
 	runtime.WindowHide(a.ctx)
+	a.windowHidden.Store(true)
+	a.updateTray()
 }
 
 // ShowWindow 显示窗口
 func (a *App) ShowWindow() {
 	runtime.WindowShow(a.ctx)
+	a.windowHidden.Store(false)
+	a.updateTray()
 }
 
-// Widget 小部件
-func (a *App) StartWidget() {
-	backend.SetupDesktopChildWidget()
+// StartWidget 进入桌面小部件模式
+func (a *App) StartWidget() error {
+	if err := a.desktop.EnterWidgetMode(a.effectiveConfig().Window.widgetOptions()); err != nil {
+		return desktopError("小部件模式", err)
+	}
+	a.setWidgetMode(true)
+	// 部分平台进入小部件模式时会重置透明度和鼠标事件
+	a.applyDesktopSettings(true)
+	return nil
 }
 
-// Quit 退出应用程序
-func (a *App) Quit() {
-	runtime.Quit(a.ctx)
+// StopWidget 退出桌面小部件模式，恢复为可以交互的普通窗口。
+// 无法退出的平台（Wayland）只取消保存的小部件模式，重启程序后恢复为普通窗口
+func (a *App) StopWidget() error {
+	if !a.desktop.Capabilities().ExitWidgetMode {
+		config := a.store.Config()
+		if state := config.Window.State; state != nil && state.Widget {
+			saved := *state
+			saved.Widget = false
+			a.setWindowState(saved)
+		}
+		return errRestartToExitWidget
+	}
+	if err := a.desktop.ExitWidgetMode(); err != nil {
+		return desktopError("退出小部件模式", err)
+	}
+	a.setWidgetMode(false)
+	return nil
 }
 
-func (a *App) ScheduleRefresh() {
-	// Stop any existing scheduled tasks
-	a.scheduler.Clear()
-
-	if a.config.UpdateInterval < 1 {
-		log.Println("UpdateInterval must > 0")
+// ToggleWidget 在小部件模式和普通窗口之间切换，返回切换后是否处于小部件模式
+func (a *App) ToggleWidget() (bool, error) {
+	state, err := a.desktop.State()
+	if err != nil {
+		return false, desktopError("获取窗口状态", err)
 	}
-
-	log.Println("Schedule refresh based on config interval")
-	// Schedule refresh based on config interval
-	a.scheduler.Every(a.config.UpdateInterval).Minutes().Do(func() {
-		// Refresh vehicle data
-		data, err := a.VehicleHomePage()
-		if err != nil {
-			// Handle error - could emit event to frontend
-			runtime.EventsEmit(a.ctx, "refreshError", err.Error())
-		} else {
-			// Emit success event to frontend
-			runtime.EventsEmit(a.ctx, "dataRefreshed", data)
+	if state.Widget {
+		if err := a.StopWidget(); err != nil {
+			return true, err
 		}
-	})
+		return false, nil
+	}
+	if err := a.StartWidget(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	// Start the scheduler
-	a.scheduler.StartAsync()
+// Quit 退出应用程序
+func (a *App) Quit() {
+	runtime.Quit(a.ctx)
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 启动方式，对应启动参数 --start，开机自启动时使用
+const (
+	startNormal = "normal" // 正常显示窗口
+	startHidden = "hidden" // 隐藏到托盘
+	startWidget = "widget" // 直接进入小部件模式
+)
+
+var startModes = map[string]bool{
+	startNormal: true,
+	startHidden: true,
+	startWidget: true,
+}
+
+// AutostartStatus 开机自启动的状态，以系统中的自启动项为准，不保存在配置文件中
+type AutostartStatus struct {
+	Enabled bool   `json:"enabled"`
+	Mode    string `json:"mode"` // 启动方式：normal、hidden、widget
+	// Location 自启动项的位置：.desktop 文件、注册表项或 LaunchAgent
+	Location string `json:"location"`
+}
+
+// GetAutostart 获取开机自启动的状态
+func (a *App) GetAutostart() (AutostartStatus, error) {
+	status, err := readAutostart()
+	if err != nil {
+		return status, fmt.Errorf("读取开机自启动失败: %v", err)
+	}
+	return status, nil
+}
+
+// EnableAutostart 登录后自动启动，mode 为 hidden 时隐藏到托盘，为 widget 时直接进入小部件模式。
+// 已经启用时更新启动方式和程序路径
+func (a *App) EnableAutostart(mode string) error {
+	if mode == "" {
+		mode = startNormal
+	}
+	if !startModes[mode] {
+		return fmt.Errorf("不支持的启动方式: %s", mode)
+	}
+	exe, err := autostartExecutable()
+	if err != nil {
+		return fmt.Errorf("获取程序路径失败: %v", err)
+	}
+	if err := installAutostart(exe, autostartArgs(mode)); err != nil {
+		return fmt.Errorf("设置开机自启动失败: %v", err)
+	}
+	return nil
+}
+
+// DisableAutostart 取消开机自启动
+func (a *App) DisableAutostart() error {
+	if err := removeAutostart(); err != nil {
+		return fmt.Errorf("取消开机自启动失败: %v", err)
+	}
+	return nil
+}
+
+// applyStartMode 窗口恢复后按启动方式进入小部件模式，隐藏到托盘由 Wails 的 StartHidden 完成
+func (a *App) applyStartMode(mode string) {
+	if mode != startWidget {
+		return
+	}
+	state, err := a.desktop.State()
+	if err == nil && state.Widget {
+		return
+	}
+	if err := a.StartWidget(); err != nil {
+		log.Println(err)
+	}
+}
+
+// autostartArgs 自启动项的启动参数
+func autostartArgs(mode string) []string {
+	if mode == startNormal {
+		return nil
+	}
+	return []string{"--start=" + mode}
+}
+
+// startModeOf 从自启动项的命令行中取出启动方式
+func startModeOf(command string) string {
+	for _, mode := range []string{startHidden, startWidget} {
+		if strings.Contains(command, "--start="+mode) {
+			return mode
+		}
+	}
+	return startNormal
+}
+
+// autostartExecutable 自启动使用的程序路径。
+// 以 AppImage 运行时程序位于临时挂载目录中，使用 AppImage 文件本身
+func autostartExecutable() (string, error) {
+	if path := os.Getenv("APPIMAGE"); path != "" {
+		return path, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
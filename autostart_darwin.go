@@ -0,0 +1,71 @@
+//go:build darwin
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bestk/zeeho-widgets/internal/fsutil"
+)
+
+// launchAgentLabel 与 Info.plist 中的 CFBundleIdentifier 一致
+const launchAgentLabel = "com.wails." + appDirName
+
+// autostartPath 当前用户的 LaunchAgent
+func autostartPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, "Library", "LaunchAgents", launchAgentLabel+".plist")
+}
+
+func readAutostart() (AutostartStatus, error) {
+	path := autostartPath()
+	status := AutostartStatus{Mode: startNormal, Location: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+	status.Enabled = true
+	status.Mode = startModeOf(string(data))
+	return status, nil
+}
+
+func installAutostart(exe string, args []string) error {
+	var arguments bytes.Buffer
+	for _, arg := range append([]string{exe}, args...) {
+		arguments.WriteString("\t\t<string>")
+		if err := xml.EscapeText(&arguments, []byte(arg)); err != nil {
+			return err
+		}
+		arguments.WriteString("</string>\n")
+	}
+
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`, launchAgentLabel, arguments.String())
+	return fsutil.WriteFileAtomic(autostartPath(), []byte(plist), 0644)
+}
+
+func removeAutostart() error {
+	if err := os.Remove(autostartPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
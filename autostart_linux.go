@@ -0,0 +1,94 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bestk/zeeho-widgets/internal/fsutil"
+)
+
+// autostartPath XDG 自启动目录中的 .desktop 文件（$XDG_CONFIG_HOME/autostart）
+func autostartPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		dir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(dir, "autostart", appDirName+".desktop")
+}
+
+func readAutostart() (AutostartStatus, error) {
+	path := autostartPath()
+	status := AutostartStatus{Mode: startNormal, Location: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+
+	status.Enabled = true
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+		switch strings.TrimSpace(key) {
+		case "Exec":
+			status.Mode = startModeOf(value)
+		case "Hidden":
+			// Hidden=true 表示该项已被删除
+			if strings.TrimSpace(value) == "true" {
+				status.Enabled = false
+			}
+		case "X-GNOME-Autostart-enabled":
+			if strings.TrimSpace(value) == "false" {
+				status.Enabled = false
+			}
+		}
+	}
+	return status, nil
+}
+
+func installAutostart(exe string, args []string) error {
+	entry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Zeeho Widget
+Comment=ZEEHO 车辆状态桌面小部件
+Exec=%s
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`, desktopExec(append([]string{exe}, args...)))
+	return fsutil.WriteFileAtomic(autostartPath(), []byte(entry), 0644)
+}
+
+func removeAutostart() error {
+	if err := os.Remove(autostartPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// desktopExec 按 Desktop Entry 规范拼接 Exec 的值：含保留字符的参数加双引号并转义，
+// 之后作为字符串值再次转义反斜杠
+func desktopExec(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+			var b strings.Builder
+			b.WriteByte('"')
+			for _, r := range arg {
+				if strings.ContainsRune("\"`$\\", r) {
+					b.WriteByte('\\')
+				}
+				b.WriteRune(r)
+			}
+			b.WriteByte('"')
+			arg = b.String()
+		}
+		quoted[i] = strings.ReplaceAll(arg, `\`, `\\`)
+	}
+	return strings.Join(quoted, " ")
+}
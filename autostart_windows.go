@@ -0,0 +1,70 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"syscall"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// 当前用户的自启动注册表项
+const (
+	runKey   = `Software\Microsoft\Windows\CurrentVersion\Run`
+	runValue = "ZeehoWidget"
+)
+
+func readAutostart() (AutostartStatus, error) {
+	status := AutostartStatus{Mode: startNormal, Location: `HKCU\` + runKey + `\` + runValue}
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.QUERY_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+	defer k.Close()
+
+	command, _, err := k.GetStringValue(runValue)
+	if errors.Is(err, registry.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+	status.Enabled = true
+	status.Mode = startModeOf(command)
+	return status, nil
+}
+
+func installAutostart(exe string, args []string) error {
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+
+	// 程序路径始终加引号，避免路径中的空格被拆分
+	command := `"` + exe + `"`
+	for _, arg := range args {
+		command += " " + syscall.EscapeArg(arg)
+	}
+	return k.SetStringValue(runValue, command)
+}
+
+func removeAutostart() error {
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+
+	if err := k.DeleteValue(runValue); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return err
+	}
+	return nil
+}
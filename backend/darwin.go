@@ -13,6 +13,13 @@ bool isMainThread() {
     return [NSThread isMainThread];
 }
 
+// 进入小部件模式前的窗口设置，退出时恢复
+static bool widgetSaved = false;
+static NSInteger savedLevel;
+static NSWindowCollectionBehavior savedBehavior;
+static NSUInteger savedStyleMask;
+static BOOL savedMovable;
+
 void setWindowLevelToDesktopImpl() {
     @autoreleasepool {
         NSArray *windows = [NSApp windows];
@@ -22,6 +29,15 @@ void setWindowLevelToDesktopImpl() {
             if (win != nil) {
                 NSLog(@"Setting window level and behaviors");
                 dispatch_async(dispatch_get_main_queue(), ^{
+                    // 重复进入时保留第一次进入前的设置
+                    if (!widgetSaved) {
+                        savedLevel = [win level];
+                        savedBehavior = [win collectionBehavior];
+                        savedStyleMask = [win styleMask];
+                        savedMovable = [win isMovableByWindowBackground];
+                        widgetSaved = true;
+                    }
+
                     // 创建桌面小部件效果
                     // 使用比桌面稍高的级别，但低于普通窗口
                     [win setLevel:kCGDesktopWindowLevel + 20];
@@ -144,13 +160,81 @@ void setWindowLevelToDesktop() {
         });
     }
 }
+// 在主线程中修改第一个窗口
+void withFirstWindow(void (^block)(NSWindow *win)) {
+    dispatch_async(dispatch_get_main_queue(), ^{
+        NSArray *windows = [NSApp windows];
+        if ([windows count] > 0) {
+            block([windows objectAtIndex:0]);
+        }
+    });
+}
+
+// 恢复进入小部件模式前的窗口级别、行为和样式
+void restoreWindowFromDesktop() {
+    withFirstWindow(^(NSWindow *win) {
+        if (!widgetSaved) {
+            return;
+        }
+        [win setLevel:savedLevel];
+        [win setCollectionBehavior:savedBehavior];
+        [win setStyleMask:savedStyleMask];
+        [win setMovableByWindowBackground:savedMovable];
+        [win makeKeyAndOrderFront:nil];
+        widgetSaved = false;
+    });
+}
+
+void setWindowAlpha(double alpha) {
+    withFirstWindow(^(NSWindow *win) {
+        [win setAlphaValue:alpha];
+    });
+}
+
+void setIgnoresMouse(bool ignores) {
+    withFirstWindow(^(NSWindow *win) {
+        [win setIgnoresMouseEvents:ignores];
+    });
+}
+
+void setWindowLevelBelow(bool below) {
+    withFirstWindow(^(NSWindow *win) {
+        [win setLevel:below ? kCGDesktopWindowLevel + 20 : NSNormalWindowLevel];
+    });
+}
 */
 import "C"
 import (
 	"fmt"
 )
 
-func SetupDesktopChildWidget() error {
+// darwinDesktop macOS 的桌面集成，小部件模式通过窗口级别实现
+type darwinDesktop struct {
+	tracker
+}
+
+// New 返回当前平台的桌面集成
+func New() DesktopIntegration {
+	return &darwinDesktop{tracker: tracker{state: DesktopState{Opacity: 1}}}
+}
+
+func (d *darwinDesktop) Capabilities() Capabilities {
+	return Capabilities{
+		Platform:       "darwin",
+		WidgetMode:     true,
+		ExitWidgetMode: true,
+		Opacity:        true,
+		// macOS 上既没有全局快捷键也没有托盘图标，开启鼠标穿透后无法再点击窗口将其关闭
+		ClickThrough:   false,
+		AlwaysOnBottom: true,
+	}
+}
+
+func (d *darwinDesktop) Prepare() error {
+	return nil
+}
+
+func (d *darwinDesktop) EnterWidgetMode(opts WidgetOptions) error {
 	fmt.Println("SetupDesktopChildWidget called with title:", window_title)
 
 	// 设置窗口为桌面级别
@@ -160,8 +244,50 @@ func SetupDesktopChildWidget() error {
 	C.setupWidgetKitIntegration()
 
 	fmt.Println("SetupDesktopChildWidget completed")
+	d.update(func(s *DesktopState) {
+		s.Widget = true
+		s.AlwaysOnBottom = true
+	})
 	return nil
 }
 
-func SetTransparentBackground() {
+func (d *darwinDesktop) ExitWidgetMode() error {
+	C.restoreWindowFromDesktop()
+	d.update(func(s *DesktopState) {
+		s.Widget = false
+		s.AlwaysOnBottom = false
+	})
+	return nil
 }
+
+func (d *darwinDesktop) SetOpacity(opacity float64) error {
+	if !validOpacity(opacity) {
+		return fmt.Errorf("透明度必须在0-1之间: %v", opacity)
+	}
+	C.setWindowAlpha(C.double(opacity))
+	d.update(func(s *DesktopState) { s.Opacity = opacity })
+	return nil
+}
+
+func (d *darwinDesktop) SetClickThrough(enabled bool) error {
+	C.setIgnoresMouse(C.bool(enabled))
+	d.update(func(s *DesktopState) { s.ClickThrough = enabled })
+	return nil
+}
+
+func (d *darwinDesktop) SetAlwaysOnBottom(enabled bool) error {
+	C.setWindowLevelBelow(C.bool(enabled))
+	d.update(func(s *DesktopState) { s.AlwaysOnBottom = enabled })
+	return nil
+}
+
+func (d *darwinDesktop) State() (DesktopState, error) {
+	return d.get(), nil
+}
+
+func (d *darwinDesktop) WatchHotkey(Hotkey, func()) (func(), error) { return nil, ErrUnsupported }
+
+func (d *darwinDesktop) Monitors() ([]Monitor, error)     { return nil, ErrUnsupported }
+func (d *darwinDesktop) WindowBounds() (Rect, error)      { return Rect{}, ErrUnsupported }
+func (d *darwinDesktop) SetWindowPosition(int, int) error { return ErrUnsupported }
+func (d *darwinDesktop) SetWindowBounds(Rect) error       { return ErrUnsupported }
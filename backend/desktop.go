@@ -0,0 +1,106 @@
+package backend
+
+import "sync"
+
+// Capabilities 当前平台支持的桌面集成功能，前端据此显示或隐藏对应的操作
+type Capabilities struct {
+	// Platform 实际使用的实现：windows、darwin、x11、wayland，不支持时为 none
+	Platform string `json:"platform"`
+	// WidgetMode 可以进入桌面小部件模式
+	WidgetMode bool `json:"widgetMode"`
+	// ExitWidgetMode 可以从小部件模式恢复为普通窗口
+	ExitWidgetMode bool `json:"exitWidgetMode"`
+	// Opacity 可以设置窗口透明度
+	Opacity bool `json:"opacity"`
+	// ClickThrough 可以让鼠标事件穿透窗口
+	ClickThrough bool `json:"clickThrough"`
+	// AlwaysOnBottom 可以将窗口固定在其它窗口下方
+	AlwaysOnBottom bool `json:"alwaysOnBottom"`
+	// Placement 可以获取显示器和窗口的屏幕坐标并移动窗口
+	Placement bool `json:"placement"`
+	// Hotkey 可以注册全局快捷键
+	Hotkey bool `json:"hotkey"`
+}
+
+// DesktopState 窗口当前的桌面集成状态
+type DesktopState struct {
+	Widget         bool    `json:"widget"`
+	Opacity        float64 `json:"opacity"` // 0-1，1 为不透明
+	ClickThrough   bool    `json:"clickThrough"`
+	AlwaysOnBottom bool    `json:"alwaysOnBottom"`
+}
+
+// DesktopIntegration 桌面集成，每个平台一个实现，由 New 根据当前平台和显示环境选择。
+// 不支持的操作返回 ErrUnsupported，Capabilities 中对应的项为 false
+type DesktopIntegration interface {
+	Capabilities() Capabilities
+
+	// Prepare 窗口创建后调用一次，完成透明背景等初始设置
+	Prepare() error
+	// EnterWidgetMode 进入桌面小部件模式：融入桌面，抵抗“显示桌面”
+	EnterWidgetMode(opts WidgetOptions) error
+	// ExitWidgetMode 恢复为普通窗口
+	ExitWidgetMode() error
+	// SetOpacity 设置窗口透明度，0-1
+	SetOpacity(opacity float64) error
+	// SetClickThrough 设置鼠标事件是否穿透窗口
+	SetClickThrough(enabled bool) error
+	// SetAlwaysOnBottom 设置窗口是否固定在其它窗口下方
+	SetAlwaysOnBottom(enabled bool) error
+	// State 查询窗口当前的状态
+	State() (DesktopState, error)
+	// WatchHotkey 注册全局快捷键，按下时在新的 goroutine 中调用 fn，返回取消注册的函数
+	WatchHotkey(hotkey Hotkey, fn func()) (stop func(), err error)
+
+	// Monitors 获取全部显示器
+	Monitors() ([]Monitor, error)
+	// WindowBounds 获取窗口在屏幕上的位置和大小
+	WindowBounds() (Rect, error)
+	// SetWindowPosition 将窗口移动到屏幕坐标 (x, y)
+	SetWindowPosition(x, y int) error
+	// SetWindowBounds 设置窗口在屏幕上的位置和大小
+	SetWindowBounds(bounds Rect) error
+}
+
+// tracker 记录通过 DesktopIntegration 设置的状态，供无法直接查询窗口状态的平台使用
+type tracker struct {
+	mu    sync.Mutex
+	state DesktopState
+}
+
+func (t *tracker) get() DesktopState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+func (t *tracker) update(fn func(s *DesktopState)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(&t.state)
+}
+
+// unsupported 没有可用的桌面集成时使用，所有操作都返回 ErrUnsupported
+type unsupported struct{}
+
+func (unsupported) Capabilities() Capabilities {
+	return Capabilities{Platform: "none"}
+}
+
+func (unsupported) Prepare() error                             { return nil }
+func (unsupported) EnterWidgetMode(WidgetOptions) error        { return ErrUnsupported }
+func (unsupported) ExitWidgetMode() error                      { return ErrUnsupported }
+func (unsupported) SetOpacity(float64) error                   { return ErrUnsupported }
+func (unsupported) SetClickThrough(bool) error                 { return ErrUnsupported }
+func (unsupported) SetAlwaysOnBottom(bool) error               { return ErrUnsupported }
+func (unsupported) State() (DesktopState, error)               { return DesktopState{Opacity: 1}, nil }
+func (unsupported) WatchHotkey(Hotkey, func()) (func(), error) { return nil, ErrUnsupported }
+func (unsupported) Monitors() ([]Monitor, error)               { return nil, ErrUnsupported }
+func (unsupported) WindowBounds() (Rect, error)                { return Rect{}, ErrUnsupported }
+func (unsupported) SetWindowPosition(int, int) error           { return ErrUnsupported }
+func (unsupported) SetWindowBounds(Rect) error                 { return ErrUnsupported }
+
+// validOpacity 检查透明度取值
+func validOpacity(opacity float64) bool {
+	return opacity > 0 && opacity <= 1
+}
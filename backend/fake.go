@@ -0,0 +1,173 @@
+package backend
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Fake 在内存中模拟窗口的 DesktopIntegration，用于测试。
+// Caps 决定支持哪些操作，Err 不为 nil 时所有修改操作都返回该错误
+type Fake struct {
+	Caps Capabilities
+	Err  error
+
+	mu       sync.Mutex
+	state    DesktopState
+	bounds   Rect
+	monitors []Monitor
+	// Options 最近一次进入小部件模式的选项
+	Options WidgetOptions
+	hotkeys map[Hotkey]func()
+}
+
+// NewFake 创建支持全部功能的 Fake，窗口位于 monitors 中
+func NewFake(monitors []Monitor, bounds Rect) *Fake {
+	return &Fake{
+		Caps: Capabilities{
+			Platform:       "fake",
+			WidgetMode:     true,
+			ExitWidgetMode: true,
+			Opacity:        true,
+			ClickThrough:   true,
+			AlwaysOnBottom: true,
+			Placement:      true,
+			Hotkey:         true,
+		},
+		state:    DesktopState{Opacity: 1},
+		bounds:   bounds,
+		monitors: monitors,
+	}
+}
+
+func (f *Fake) Capabilities() Capabilities {
+	return f.Caps
+}
+
+// do 检查是否支持并在持有锁时执行修改
+func (f *Fake) do(supported bool, fn func()) error {
+	if !supported {
+		return ErrUnsupported
+	}
+	if f.Err != nil {
+		return f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn()
+	return nil
+}
+
+func (f *Fake) Prepare() error {
+	return f.Err
+}
+
+func (f *Fake) EnterWidgetMode(opts WidgetOptions) error {
+	return f.do(f.Caps.WidgetMode, func() {
+		f.state.Widget = true
+		f.Options = opts
+	})
+}
+
+func (f *Fake) ExitWidgetMode() error {
+	return f.do(f.Caps.ExitWidgetMode, func() {
+		f.state.Widget = false
+	})
+}
+
+func (f *Fake) SetOpacity(opacity float64) error {
+	if !validOpacity(opacity) {
+		return fmt.Errorf("透明度必须在0-1之间: %v", opacity)
+	}
+	return f.do(f.Caps.Opacity, func() {
+		f.state.Opacity = opacity
+	})
+}
+
+func (f *Fake) SetClickThrough(enabled bool) error {
+	return f.do(f.Caps.ClickThrough, func() {
+		f.state.ClickThrough = enabled
+	})
+}
+
+func (f *Fake) SetAlwaysOnBottom(enabled bool) error {
+	return f.do(f.Caps.AlwaysOnBottom, func() {
+		f.state.AlwaysOnBottom = enabled
+	})
+}
+
+func (f *Fake) State() (DesktopState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state, nil
+}
+
+func (f *Fake) WatchHotkey(hotkey Hotkey, fn func()) (func(), error) {
+	err := f.do(f.Caps.Hotkey, func() {
+		if f.hotkeys == nil {
+			f.hotkeys = make(map[Hotkey]func())
+		}
+		f.hotkeys[hotkey] = fn
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.hotkeys, hotkey)
+	}, nil
+}
+
+// Press 模拟按下快捷键，在当前 goroutine 中调用注册的函数，返回快捷键是否已注册
+func (f *Fake) Press(hotkey Hotkey) bool {
+	f.mu.Lock()
+	fn, ok := f.hotkeys[hotkey]
+	f.mu.Unlock()
+	if ok {
+		fn()
+	}
+	return ok
+}
+
+func (f *Fake) Monitors() ([]Monitor, error) {
+	if !f.Caps.Placement {
+		return nil, ErrUnsupported
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	monitors := make([]Monitor, len(f.monitors))
+	copy(monitors, f.monitors)
+	for i := range monitors {
+		monitors[i].Current = monitors[i].Bounds.X <= f.bounds.X && f.bounds.X < monitors[i].Bounds.X+monitors[i].Bounds.Width &&
+			monitors[i].Bounds.Y <= f.bounds.Y && f.bounds.Y < monitors[i].Bounds.Y+monitors[i].Bounds.Height
+	}
+	return monitors, nil
+}
+
+// SetMonitors 模拟连接或断开显示器
+func (f *Fake) SetMonitors(monitors []Monitor) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.monitors = monitors
+}
+
+func (f *Fake) WindowBounds() (Rect, error) {
+	if !f.Caps.Placement {
+		return Rect{}, ErrUnsupported
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.bounds, nil
+}
+
+func (f *Fake) SetWindowPosition(x, y int) error {
+	return f.do(f.Caps.Placement, func() {
+		f.bounds.X, f.bounds.Y = x, y
+	})
+}
+
+func (f *Fake) SetWindowBounds(bounds Rect) error {
+	return f.do(f.Caps.Placement, func() {
+		f.bounds = bounds
+	})
+}
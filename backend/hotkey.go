@@ -0,0 +1,83 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hotkey 全局快捷键，例如 Ctrl+Alt+Z
+type Hotkey struct {
+	Ctrl  bool
+	Alt   bool
+	Shift bool
+	Super bool
+	// Key 主键：A-Z、0-9 或 F1-F12
+	Key string
+}
+
+// ParseHotkey 解析 "Ctrl+Alt+Z" 形式的快捷键，不区分大小写，至少需要一个修饰键
+func ParseHotkey(s string) (Hotkey, error) {
+	var h Hotkey
+	parts := strings.Split(s, "+")
+	for i, part := range parts {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		if i == len(parts)-1 {
+			if !validKey(part) {
+				return Hotkey{}, fmt.Errorf("不支持的按键: %s", part)
+			}
+			h.Key = part
+			break
+		}
+		switch part {
+		case "CTRL", "CONTROL":
+			h.Ctrl = true
+		case "ALT":
+			h.Alt = true
+		case "SHIFT":
+			h.Shift = true
+		case "SUPER", "WIN", "CMD":
+			h.Super = true
+		default:
+			return Hotkey{}, fmt.Errorf("不支持的修饰键: %s", part)
+		}
+	}
+	if !h.Ctrl && !h.Alt && !h.Shift && !h.Super {
+		return Hotkey{}, fmt.Errorf("快捷键至少需要一个修饰键: %s", s)
+	}
+	return h, nil
+}
+
+// validKey A-Z、0-9 或 F1-F12
+func validKey(key string) bool {
+	if len(key) == 1 {
+		c := key[0]
+		return c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+	}
+	return functionKey(key) > 0
+}
+
+// functionKey 返回 F1-F12 的序号，不是功能键时返回 0
+func functionKey(key string) int {
+	var n int
+	if _, err := fmt.Sscanf(key, "F%d", &n); err != nil || fmt.Sprintf("F%d", n) != key || n < 1 || n > 12 {
+		return 0
+	}
+	return n
+}
+
+func (h Hotkey) String() string {
+	var parts []string
+	if h.Ctrl {
+		parts = append(parts, "Ctrl")
+	}
+	if h.Alt {
+		parts = append(parts, "Alt")
+	}
+	if h.Shift {
+		parts = append(parts, "Shift")
+	}
+	if h.Super {
+		parts = append(parts, "Super")
+	}
+	return strings.Join(append(parts, h.Key), "+")
+}
@@ -2,9 +2,222 @@
 
 package backend
 
-func SetupDesktopChildWidget(windowTitle string) error {
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+// New 返回当前显示环境的桌面集成。Wayland 下使用 wlr-layer-shell，
+// X11 下通过 EWMH 设置窗口类型和状态。GDK_BACKEND=x11 时 GTK 通过 XWayland 运行，只能使用 X11 的方式
+func New() DesktopIntegration {
+	if os.Getenv("WAYLAND_DISPLAY") != "" && os.Getenv("GDK_BACKEND") != "x11" {
+		return &waylandDesktop{tracker: tracker{state: DesktopState{Opacity: 1}}}
+	}
+	if os.Getenv("DISPLAY") != "" {
+		return &x11Desktop{tracker: tracker{state: DesktopState{Opacity: 1}}}
+	}
+	return unsupported{}
+}
+
+// x11Desktop X11 的桌面集成，每次操作单独连接 X Server 并查找窗口
+type x11Desktop struct {
+	tracker
+
+	mu sync.Mutex
+	// saved 进入小部件模式前的窗口属性，不在小部件模式时为 nil
+	saved savedProperties
+}
+
+func (d *x11Desktop) Capabilities() Capabilities {
+	return Capabilities{
+		Platform:       "x11",
+		WidgetMode:     true,
+		ExitWidgetMode: true,
+		Opacity:        true,
+		ClickThrough:   true,
+		AlwaysOnBottom: true,
+		Placement:      true,
+		Hotkey:         true,
+	}
+}
+
+// withWindow 连接 X Server，查找窗口后执行 fn
+func (d *x11Desktop) withWindow(fn func(x *x11, win xproto.Window) error) error {
+	x, err := newX11()
+	if err != nil {
+		return err
+	}
+	defer x.close()
+
+	win, err := x.findWindow(window_title)
+	if err != nil {
+		return err
+	}
+	return fn(x, win)
+}
+
+func (d *x11Desktop) Prepare() error {
+	return nil
+}
+
+// EnterWidgetMode 通过 EWMH 将窗口设置为桌面类型，抵抗显示桌面，在所有工作区显示
+func (d *x11Desktop) EnterWidgetMode(opts WidgetOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	err := d.withWindow(func(x *x11, win xproto.Window) error {
+		// 重复进入时保留第一次进入前的属性
+		if d.saved == nil {
+			saved, err := x.saveProperties(win, widgetProperties...)
+			if err != nil {
+				return err
+			}
+			d.saved = saved
+		}
+		return x.setupDesktopWidget(win)
+	})
+	if err != nil {
+		return err
+	}
+	d.update(func(s *DesktopState) {
+		s.Widget = true
+		s.AlwaysOnBottom = true
+	})
+	return nil
+}
+
+// ExitWidgetMode 恢复进入小部件模式前的窗口类型和状态
+func (d *x11Desktop) ExitWidgetMode() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.saved == nil {
+		return nil
+	}
+	err := d.withWindow(func(x *x11, win xproto.Window) error {
+		return x.restoreWidget(win, d.saved)
+	})
+	if err != nil {
+		return err
+	}
+	d.saved = nil
+	d.update(func(s *DesktopState) {
+		s.Widget = false
+		s.AlwaysOnBottom = false
+	})
+	return nil
+}
+
+func (d *x11Desktop) SetOpacity(opacity float64) error {
+	if !validOpacity(opacity) {
+		return fmt.Errorf("透明度必须在0-1之间: %v", opacity)
+	}
+	err := d.withWindow(func(x *x11, win xproto.Window) error {
+		return x.setOpacity(win, opacity)
+	})
+	if err != nil {
+		return err
+	}
+	d.update(func(s *DesktopState) { s.Opacity = opacity })
+	return nil
+}
+
+func (d *x11Desktop) SetClickThrough(enabled bool) error {
+	err := d.withWindow(func(x *x11, win xproto.Window) error {
+		return x.setClickThrough(win, enabled)
+	})
+	if err != nil {
+		return err
+	}
+	d.update(func(s *DesktopState) { s.ClickThrough = enabled })
 	return nil
 }
 
-func SetTransparentBackground() {
+func (d *x11Desktop) SetAlwaysOnBottom(enabled bool) error {
+	err := d.withWindow(func(x *x11, win xproto.Window) error {
+		return x.setBelow(win, enabled)
+	})
+	if err != nil {
+		return err
+	}
+	d.update(func(s *DesktopState) { s.AlwaysOnBottom = enabled })
+	return nil
+}
+
+// WatchHotkey 使用单独的连接抓取快捷键并等待按键事件，关闭连接后停止
+func (d *x11Desktop) WatchHotkey(hotkey Hotkey, fn func()) (func(), error) {
+	x, err := newX11()
+	if err != nil {
+		return nil, err
+	}
+	code, err := x.grabHotkey(hotkey)
+	if err != nil {
+		x.close()
+		return nil, fmt.Errorf("注册快捷键 %s 失败: %v", hotkey, err)
+	}
+
+	go func() {
+		for {
+			ev, err := x.conn.WaitForEvent()
+			if ev == nil && err == nil {
+				// 连接已关闭
+				return
+			}
+			if press, ok := ev.(xproto.KeyPressEvent); ok && press.Detail == code {
+				go fn()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(x.close) }, nil
+}
+
+func (d *x11Desktop) State() (DesktopState, error) {
+	return d.get(), nil
+}
+
+// Monitors 通过 RandR 获取显示器，找不到窗口时不标记当前显示器
+func (d *x11Desktop) Monitors() ([]Monitor, error) {
+	x, err := newX11()
+	if err != nil {
+		return nil, err
+	}
+	defer x.close()
+
+	monitors, err := x.monitors()
+	if err != nil {
+		return nil, err
+	}
+	if win, err := x.findWindow(window_title); err == nil {
+		if bounds, err := x.windowBounds(win); err == nil {
+			markCurrent(monitors, bounds)
+		}
+	}
+	return monitors, nil
+}
+
+func (d *x11Desktop) WindowBounds() (Rect, error) {
+	var bounds Rect
+	err := d.withWindow(func(x *x11, win xproto.Window) error {
+		var err error
+		bounds, err = x.windowBounds(win)
+		return err
+	})
+	return bounds, err
+}
+
+func (d *x11Desktop) SetWindowPosition(px, py int) error {
+	return d.withWindow(func(x *x11, win xproto.Window) error {
+		return x.setWindowBounds(win, Rect{X: px, Y: py})
+	})
+}
+
+func (d *x11Desktop) SetWindowBounds(bounds Rect) error {
+	return d.withWindow(func(x *x11, win xproto.Window) error {
+		return x.setWindowBounds(win, bounds)
+	})
 }
@@ -0,0 +1,28 @@
+package backend
+
+import "errors"
+
+// ErrUnsupported 当前平台没有实现该功能
+var ErrUnsupported = errors.New("当前平台不支持该操作")
+
+// Rect 矩形区域，坐标为虚拟桌面上的物理像素
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Monitor 显示器信息
+type Monitor struct {
+	// ID 显示器标识，重新插拔后保持不变（Windows 上为设备名，如 \\.\DISPLAY1）
+	ID      string `json:"id"`
+	Primary bool   `json:"primary"`
+	// Current 窗口当前所在的显示器
+	Current bool `json:"current"`
+	Bounds  Rect `json:"bounds"`
+	// WorkArea 去掉任务栏、Dock 之后的可用区域
+	WorkArea Rect `json:"workArea"`
+	// Scale 缩放比例，1 表示 96 DPI
+	Scale float64 `json:"scale"`
+}
@@ -0,0 +1,319 @@
+//go:build linux
+
+package backend
+
+/*
+#cgo LDFLAGS: -ldl
+
+#define _GNU_SOURCE
+#include <dlfcn.h>
+#include <semaphore.h>
+#include <stddef.h>
+#include <stdlib.h>
+#include <string.h>
+
+// GTK、GLib 已经由 Wails 加载，gtk-layer-shell 按需加载，缺少时只影响小部件模式，
+// 因此全部通过 dlsym 调用，编译时不需要这些库的头文件
+
+typedef struct list { void *data; struct list *next; struct list *prev; } list;
+
+enum { EDGE_LEFT = 0, EDGE_RIGHT = 1, EDGE_TOP = 2, EDGE_BOTTOM = 3 };
+
+enum { OP_LAYER_SHELL = 0, OP_OPACITY = 1, OP_CLICK_THROUGH = 2 };
+
+typedef struct {
+	int op;
+	const char *title;
+	int layer;
+	int anchor[4];
+	int margin[4];
+	double opacity;
+	int enabled;
+	const char *error;
+	sem_t done;
+} layer_request;
+
+static void *sym(void *lib, const char *name) {
+	return dlsym(lib, name);
+}
+
+// find_window 按标题查找 GTK 顶层窗口
+static void *find_window(layer_request *req) {
+	list *(*list_toplevels)(void) = sym(RTLD_DEFAULT, "gtk_window_list_toplevels");
+	const char *(*get_title)(void *) = sym(RTLD_DEFAULT, "gtk_window_get_title");
+	void (*list_free)(list *) = sym(RTLD_DEFAULT, "g_list_free");
+	if (!list_toplevels || !get_title || !list_free) {
+		req->error = "当前进程没有使用 GTK";
+		return NULL;
+	}
+
+	void *window = NULL;
+	list *toplevels = list_toplevels();
+	for (list *l = toplevels; l != NULL; l = l->next) {
+		const char *title = get_title(l->data);
+		if (title != NULL && strcmp(title, req->title) == 0) {
+			window = l->data;
+			break;
+		}
+	}
+	list_free(toplevels);
+	if (window == NULL) {
+		req->error = "找不到窗口";
+	}
+	return window;
+}
+
+static void setup_layer_shell(layer_request *req, void *window) {
+	void *lib = dlopen("libgtk-layer-shell.so.0", RTLD_NOW | RTLD_GLOBAL);
+	if (lib == NULL) {
+		req->error = "未找到 gtk-layer-shell 库（libgtk-layer-shell.so.0）";
+		return;
+	}
+
+	void (*widget_hide)(void *) = sym(RTLD_DEFAULT, "gtk_widget_hide");
+	void (*widget_unrealize)(void *) = sym(RTLD_DEFAULT, "gtk_widget_unrealize");
+	void (*widget_show)(void *) = sym(RTLD_DEFAULT, "gtk_widget_show");
+
+	int (*is_supported)(void) = sym(lib, "gtk_layer_is_supported");
+	void (*init_for_window)(void *) = sym(lib, "gtk_layer_init_for_window");
+	void (*set_namespace)(void *, const char *) = sym(lib, "gtk_layer_set_namespace");
+	void (*set_layer)(void *, int) = sym(lib, "gtk_layer_set_layer");
+	void (*set_anchor)(void *, int, int) = sym(lib, "gtk_layer_set_anchor");
+	void (*set_margin)(void *, int, int) = sym(lib, "gtk_layer_set_margin");
+
+	if (!widget_hide || !widget_unrealize || !widget_show) {
+		req->error = "当前进程没有使用 GTK";
+		return;
+	}
+	if (!init_for_window || !set_layer || !set_anchor || !set_margin) {
+		req->error = "gtk-layer-shell 版本过旧";
+		return;
+	}
+	// gtk_layer_is_supported 从 0.6 开始提供，混成器不支持 wlr-layer-shell 或 GTK 没有使用 Wayland 时返回 0
+	if (is_supported && !is_supported()) {
+		req->error = "混成器不支持 wlr-layer-shell 协议";
+		return;
+	}
+
+	// layer-shell 只能在窗口实现之前初始化，先隐藏并销毁原来的 xdg-toplevel 表面
+	widget_hide(window);
+	widget_unrealize(window);
+
+	init_for_window(window);
+	if (set_namespace) {
+		set_namespace(window, "zeeho-widgets");
+	}
+	set_layer(window, req->layer);
+	for (int edge = 0; edge < 4; edge++) {
+		set_anchor(window, edge, req->anchor[edge]);
+		set_margin(window, edge, req->margin[edge]);
+	}
+
+	widget_show(window);
+}
+
+static void set_opacity(layer_request *req, void *window) {
+	void (*widget_set_opacity)(void *, double) = sym(RTLD_DEFAULT, "gtk_widget_set_opacity");
+	if (!widget_set_opacity) {
+		req->error = "当前进程没有使用 GTK";
+		return;
+	}
+	widget_set_opacity(window, req->opacity);
+}
+
+// set_click_through 将输入区域设置为空区域，鼠标事件会穿透到下方窗口，区域为 NULL 时恢复
+static void set_click_through(layer_request *req, void *window) {
+	void (*input_shape)(void *, void *) = sym(RTLD_DEFAULT, "gtk_widget_input_shape_combine_region");
+	void *(*region_create)(void) = sym(RTLD_DEFAULT, "cairo_region_create");
+	void (*region_destroy)(void *) = sym(RTLD_DEFAULT, "cairo_region_destroy");
+	if (!input_shape || !region_create || !region_destroy) {
+		req->error = "当前进程没有使用 GTK";
+		return;
+	}
+	if (req->enabled) {
+		void *region = region_create();
+		input_shape(window, region);
+		region_destroy(region);
+	} else {
+		input_shape(window, NULL);
+	}
+}
+
+static void handle(layer_request *req) {
+	void *window = find_window(req);
+	if (window == NULL) {
+		return;
+	}
+	switch (req->op) {
+	case OP_LAYER_SHELL:
+		setup_layer_shell(req, window);
+		break;
+	case OP_OPACITY:
+		set_opacity(req, window);
+		break;
+	case OP_CLICK_THROUGH:
+		set_click_through(req, window);
+		break;
+	}
+}
+
+static int on_main_thread(layer_request *req) {
+	handle(req);
+	sem_post(&req->done);
+	return 0; // G_SOURCE_REMOVE
+}
+
+// run_on_main 在 GTK 主线程中执行并等待完成
+static void run_on_main(layer_request *req) {
+	void *(*context_default)(void) = sym(RTLD_DEFAULT, "g_main_context_default");
+	int (*context_is_owner)(void *) = sym(RTLD_DEFAULT, "g_main_context_is_owner");
+	unsigned (*idle_add)(int (*)(layer_request *), void *) = sym(RTLD_DEFAULT, "g_idle_add");
+	if (!context_default || !context_is_owner || !idle_add) {
+		req->error = "当前进程没有使用 GLib";
+		return;
+	}
+
+	if (context_is_owner(context_default())) {
+		handle(req);
+		return;
+	}
+	sem_init(&req->done, 0, 0);
+	idle_add(on_main_thread, req);
+	sem_wait(&req->done);
+	sem_destroy(&req->done);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// layer-shell 的层，见 GtkLayerShellLayer
+var layers = map[string]C.int{
+	"background": 0,
+	"bottom":     1,
+}
+
+// waylandDesktop Wayland 的桌面集成，小部件模式通过 wlr-layer-shell 实现。
+// Wayland 下程序无法获取和设置窗口的屏幕坐标，也不能调整窗口的层级
+type waylandDesktop struct {
+	tracker
+}
+
+func (d *waylandDesktop) Capabilities() Capabilities {
+	return Capabilities{
+		Platform:     "wayland",
+		WidgetMode:   true,
+		Opacity:      true,
+		ClickThrough: true,
+	}
+}
+
+func (d *waylandDesktop) Prepare() error {
+	return nil
+}
+
+// EnterWidgetMode 通过 wlr-layer-shell 将窗口放到桌面背景层或底层，
+// 按锚点和边距固定在屏幕上，不受其它窗口和“显示桌面”影响
+func (d *waylandDesktop) EnterWidgetMode(opts WidgetOptions) error {
+	layer := opts.Layer
+	if layer == "" {
+		layer = "bottom"
+	}
+	l, ok := layers[layer]
+	if !ok {
+		return fmt.Errorf("未知的层: %s", layer)
+	}
+
+	err := runOnMain(C.OP_LAYER_SHELL, func(req *C.layer_request) {
+		req.layer = l
+		// 锚点为 0 贴住左/上边缘，为 1 贴住右/下边缘，其它值不锚定该方向，由混成器居中
+		edge := func(anchored bool) C.int {
+			if anchored {
+				return 1
+			}
+			return 0
+		}
+		req.anchor[C.EDGE_LEFT] = edge(opts.AnchorX == 0)
+		req.anchor[C.EDGE_RIGHT] = edge(opts.AnchorX == 1)
+		req.anchor[C.EDGE_TOP] = edge(opts.AnchorY == 0)
+		req.anchor[C.EDGE_BOTTOM] = edge(opts.AnchorY == 1)
+		req.margin[C.EDGE_LEFT] = C.int(opts.Margins.Left)
+		req.margin[C.EDGE_RIGHT] = C.int(opts.Margins.Right)
+		req.margin[C.EDGE_TOP] = C.int(opts.Margins.Top)
+		req.margin[C.EDGE_BOTTOM] = C.int(opts.Margins.Bottom)
+	})
+	if err != nil {
+		return fmt.Errorf("设置 layer-shell 失败: %v", err)
+	}
+	d.update(func(s *DesktopState) { s.Widget = true })
+	return nil
+}
+
+func (d *waylandDesktop) ExitWidgetMode() error {
+	return ErrUnsupported
+}
+
+func (d *waylandDesktop) SetOpacity(opacity float64) error {
+	if !validOpacity(opacity) {
+		return fmt.Errorf("透明度必须在0-1之间: %v", opacity)
+	}
+	err := runOnMain(C.OP_OPACITY, func(req *C.layer_request) {
+		req.opacity = C.double(opacity)
+	})
+	if err != nil {
+		return fmt.Errorf("设置透明度失败: %v", err)
+	}
+	d.update(func(s *DesktopState) { s.Opacity = opacity })
+	return nil
+}
+
+func (d *waylandDesktop) SetClickThrough(enabled bool) error {
+	err := runOnMain(C.OP_CLICK_THROUGH, func(req *C.layer_request) {
+		if enabled {
+			req.enabled = 1
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("设置鼠标穿透失败: %v", err)
+	}
+	d.update(func(s *DesktopState) { s.ClickThrough = enabled })
+	return nil
+}
+
+func (d *waylandDesktop) SetAlwaysOnBottom(enabled bool) error {
+	return ErrUnsupported
+}
+
+func (d *waylandDesktop) State() (DesktopState, error) {
+	return d.get(), nil
+}
+
+// WatchHotkey Wayland 不允许普通程序注册全局快捷键
+func (d *waylandDesktop) WatchHotkey(Hotkey, func()) (func(), error) { return nil, ErrUnsupported }
+
+func (d *waylandDesktop) Monitors() ([]Monitor, error)     { return nil, ErrUnsupported }
+func (d *waylandDesktop) WindowBounds() (Rect, error)      { return Rect{}, ErrUnsupported }
+func (d *waylandDesktop) SetWindowPosition(int, int) error { return ErrUnsupported }
+func (d *waylandDesktop) SetWindowBounds(Rect) error       { return ErrUnsupported }
+
+// runOnMain 在 GTK 主线程中对窗口执行 op，fill 填写操作的参数
+func runOnMain(op C.int, fill func(req *C.layer_request)) error {
+	title := C.CString(window_title)
+	defer C.free(unsafe.Pointer(title))
+
+	req := (*C.layer_request)(C.calloc(1, C.sizeof_layer_request))
+	defer C.free(unsafe.Pointer(req))
+	req.op = op
+	req.title = title
+	fill(req)
+
+	C.run_on_main(req)
+	if req.error != nil {
+		return errors.New(C.GoString(req.error))
+	}
+	return nil
+}
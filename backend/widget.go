@@ -0,0 +1,19 @@
+package backend
+
+// Insets 四边的距离（逻辑像素）
+type Insets struct {
+	Top    int
+	Right  int
+	Bottom int
+	Left   int
+}
+
+// WidgetOptions 小部件模式的选项，目前只有 Wayland layer-shell 使用
+type WidgetOptions struct {
+	// Layer 窗口所在的层：background 或 bottom，默认 bottom
+	Layer string
+	// AnchorX、AnchorY 为 0 时贴住左/上边缘，为 1 时贴住右/下边缘，其它值居中
+	AnchorX, AnchorY float64
+	// Margins 与锚定的边缘之间的距离
+	Margins Insets
+}
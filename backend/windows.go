@@ -5,10 +5,10 @@ package backend
 import (
 	"fmt"
 	"log"
+	"runtime"
+	"sync"
 	"syscall"
 	"unsafe"
-
-	"github.com/lxn/win"
 )
 
 var (
@@ -25,10 +25,348 @@ var (
 	procSendMessage                = user32.NewProc("SendMessageW")
 	procEnumWindows                = user32.NewProc("EnumWindows")
 	procGetClassNameW              = user32.NewProc("GetClassNameW")
+	procEnumDisplayMonitors        = user32.NewProc("EnumDisplayMonitors")
+	procGetMonitorInfoW            = user32.NewProc("GetMonitorInfoW")
+	procMonitorFromWindow          = user32.NewProc("MonitorFromWindow")
+	procGetWindowRect              = user32.NewProc("GetWindowRect")
+	procGetParent                  = user32.NewProc("GetParent")
+	procMapWindowPoints            = user32.NewProc("MapWindowPoints")
+	procGetLayeredWindowAttributes = user32.NewProc("GetLayeredWindowAttributes")
+	procRegisterHotKey             = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey           = user32.NewProc("UnregisterHotKey")
+	procGetMessageW                = user32.NewProc("GetMessageW")
+	procPostThreadMessageW         = user32.NewProc("PostThreadMessageW")
+
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+
+	shcore               = syscall.NewLazyDLL("shcore.dll")
+	procGetDpiForMonitor = shcore.NewProc("GetDpiForMonitor")
+)
+
+// syscall.NewCallback 能创建的回调数量有限且不会释放，枚举窗口和显示器的回调只创建一次，
+// 结果通过 enumMu 保护的包级变量传递
+var (
+	enumMu sync.Mutex
+
+	enumWorkerW        uintptr // findWorkerW 找到的 WorkerW 窗口
+	enumWorkerCallback = syscall.NewCallback(enumWorker)
+
+	enumCurrent         uintptr   // 窗口所在的显示器
+	enumMonitors        []Monitor // Monitors 枚举到的显示器
+	enumMonitorCallback = syscall.NewCallback(enumMonitor)
+)
+
+const (
+	gwlExstyle = uintptr(^uint32(19)) // -20
+
+	WS_EX_TRANSPARENT = 0x00000020 // 鼠标事件穿透到下方窗口
+	WS_EX_TOOLWINDOW  = 0x00000080 // 不在 Alt+Tab 显示
+	WS_EX_LAYERED     = 0x00080000 // 支持透明
+	WS_EX_NOACTIVATE  = 0x08000000 // 不激活窗口
+	WS_EX_APPWINDOW   = 0x00040000 // 在任务栏显示（要移除）
+
+	LWA_ALPHA = 0x2
+
+	SWP_NOSIZE       = 0x0001
+	SWP_NOMOVE       = 0x0002
+	SWP_NOZORDER     = 0x0004
+	SWP_NOACTIVATE   = 0x0010
+	SWP_FRAMECHANGED = 0x0020
+	SWP_SHOWWINDOW   = 0x0040
+
+	HWND_TOP    = 0
+	HWND_BOTTOM = 1
 )
 
+// 小部件模式修改的扩展样式，退出时恢复为进入前的值
+const widgetExStyles = WS_EX_TOOLWINDOW | WS_EX_NOACTIVATE | WS_EX_APPWINDOW
+
+// windowsDesktop Windows 的桌面集成，小部件模式下窗口是桌面 WorkerW 的子窗口
+type windowsDesktop struct {
+	tracker
+
+	mu sync.Mutex
+	// saved 进入小部件模式前的父窗口和扩展样式，不在小部件模式时为 nil
+	saved *savedWindow
+}
+
+type savedWindow struct {
+	parent  uintptr
+	exStyle uintptr
+}
+
+// New 返回当前平台的桌面集成
+func New() DesktopIntegration {
+	return &windowsDesktop{tracker: tracker{state: DesktopState{Opacity: 1}}}
+}
+
+func (d *windowsDesktop) Capabilities() Capabilities {
+	return Capabilities{
+		Platform:       "windows",
+		WidgetMode:     true,
+		ExitWidgetMode: true,
+		Opacity:        true,
+		ClickThrough:   true,
+		AlwaysOnBottom: true,
+		Placement:      true,
+		Hotkey:         true,
+	}
+}
+
+// Prepare 设置 WS_EX_LAYERED，支持透明背景
+func (d *windowsDesktop) Prepare() error {
+	hwnd, err := findWindow()
+	if err != nil {
+		return err
+	}
+	setExStyle(hwnd, WS_EX_LAYERED, 0)
+	return nil
+}
+
+func (d *windowsDesktop) EnterWidgetMode(opts WidgetOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// 重复进入时保留第一次进入前的状态
+	if d.saved == nil {
+		hwnd, err := findWindow()
+		if err != nil {
+			return err
+		}
+		parent, _, _ := procGetParent.Call(hwnd)
+		exStyle, _, _ := procGetWindowLongW.Call(hwnd, gwlExstyle)
+		d.saved = &savedWindow{parent: parent, exStyle: exStyle}
+	}
+
+	if err := setupDesktopChildWidget(); err != nil {
+		return err
+	}
+	d.update(func(s *DesktopState) { s.Widget = true })
+	return nil
+}
+
+// ExitWidgetMode 恢复进入小部件模式前的父窗口和扩展样式，窗口保持在屏幕上原来的位置并移到最前
+func (d *windowsDesktop) ExitWidgetMode() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.saved == nil {
+		return nil
+	}
+	hwnd, err := findWindow()
+	if err != nil {
+		return err
+	}
+
+	// 子窗口的坐标相对于 WorkerW，先记录屏幕坐标，恢复父窗口后再移动回去
+	var r rect
+	procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&r)))
+
+	if ret, _, err := procSetParent.Call(hwnd, d.saved.parent); ret == 0 {
+		return fmt.Errorf("恢复父窗口失败: %v", err)
+	}
+
+	// 只恢复小部件模式修改的样式，保留透明度、鼠标穿透等设置
+	exStyle, _, _ := procGetWindowLongW.Call(hwnd, gwlExstyle)
+	procSetWindowLongW.Call(hwnd, gwlExstyle, exStyle&^widgetExStyles|d.saved.exStyle&widgetExStyles)
+
+	if ret, _, err := procSetWindowPos.Call(hwnd, HWND_TOP, uintptr(r.Left), uintptr(r.Top), 0, 0,
+		uintptr(SWP_NOSIZE|SWP_FRAMECHANGED|SWP_SHOWWINDOW)); ret == 0 {
+		return fmt.Errorf("恢复窗口位置失败: %v", err)
+	}
+
+	d.saved = nil
+	d.update(func(s *DesktopState) {
+		s.Widget = false
+		s.AlwaysOnBottom = false
+	})
+	return nil
+}
+
+// setExStyle 设置和清除扩展样式
+func setExStyle(hwnd uintptr, set, clear uintptr) {
+	exStyle, _, _ := procGetWindowLongW.Call(hwnd, gwlExstyle)
+	procSetWindowLongW.Call(hwnd, gwlExstyle, (exStyle|set)&^clear)
+}
+
+func (d *windowsDesktop) SetOpacity(opacity float64) error {
+	if !validOpacity(opacity) {
+		return fmt.Errorf("透明度必须在0-1之间: %v", opacity)
+	}
+	hwnd, err := findWindow()
+	if err != nil {
+		return err
+	}
+	setExStyle(hwnd, WS_EX_LAYERED, 0)
+	if ret, _, err := procSetLayeredWindowAttributes.Call(hwnd, 0, uintptr(byte(opacity*255+0.5)), LWA_ALPHA); ret == 0 {
+		return fmt.Errorf("设置透明度失败: %v", err)
+	}
+	d.update(func(s *DesktopState) { s.Opacity = opacity })
+	return nil
+}
+
+func (d *windowsDesktop) SetClickThrough(enabled bool) error {
+	hwnd, err := findWindow()
+	if err != nil {
+		return err
+	}
+	if enabled {
+		setExStyle(hwnd, WS_EX_TRANSPARENT|WS_EX_LAYERED, 0)
+	} else {
+		setExStyle(hwnd, 0, WS_EX_TRANSPARENT)
+	}
+	d.update(func(s *DesktopState) { s.ClickThrough = enabled })
+	return nil
+}
+
+func (d *windowsDesktop) SetAlwaysOnBottom(enabled bool) error {
+	hwnd, err := findWindow()
+	if err != nil {
+		return err
+	}
+	if enabled {
+		if ret, _, err := procSetWindowPos.Call(hwnd, HWND_BOTTOM, 0, 0, 0, 0,
+			uintptr(SWP_NOMOVE|SWP_NOSIZE|SWP_NOACTIVATE)); ret == 0 {
+			return fmt.Errorf("设置窗口层级失败: %v", err)
+		}
+	}
+	d.update(func(s *DesktopState) { s.AlwaysOnBottom = enabled })
+	return nil
+}
+
+// State 从窗口样式读取小部件模式、透明度和鼠标穿透，其余使用记录的状态
+func (d *windowsDesktop) State() (DesktopState, error) {
+	state := d.get()
+	hwnd, err := findWindow()
+	if err != nil {
+		return state, err
+	}
+
+	parent, _, _ := procGetParent.Call(hwnd)
+	state.Widget = parent != 0
+
+	exStyle, _, _ := procGetWindowLongW.Call(hwnd, gwlExstyle)
+	state.ClickThrough = exStyle&WS_EX_TRANSPARENT != 0
+
+	var alpha byte
+	var flags uint32
+	if ret, _, _ := procGetLayeredWindowAttributes.Call(hwnd, 0, uintptr(unsafe.Pointer(&alpha)),
+		uintptr(unsafe.Pointer(&flags))); ret != 0 && flags&LWA_ALPHA != 0 {
+		state.Opacity = float64(alpha) / 255
+	}
+	return state, nil
+}
+
+// WatchHotkey 通过 RegisterHotKey 注册全局快捷键。
+// WM_HOTKEY 发送到注册线程的消息队列，因此在锁定的系统线程中运行消息循环
+func (d *windowsDesktop) WatchHotkey(hotkey Hotkey, fn func()) (func(), error) {
+	const (
+		MOD_ALT      = 0x0001
+		MOD_CONTROL  = 0x0002
+		MOD_SHIFT    = 0x0004
+		MOD_WIN      = 0x0008
+		MOD_NOREPEAT = 0x4000
+
+		WM_QUIT   = 0x0012
+		WM_HOTKEY = 0x0312
+
+		hotkeyID = 1
+	)
+
+	mods := uintptr(MOD_NOREPEAT)
+	if hotkey.Ctrl {
+		mods |= MOD_CONTROL
+	}
+	if hotkey.Alt {
+		mods |= MOD_ALT
+	}
+	if hotkey.Shift {
+		mods |= MOD_SHIFT
+	}
+	if hotkey.Super {
+		mods |= MOD_WIN
+	}
+	// A-Z、0-9 的虚拟键码与 ASCII 相同，F1 为 0x70
+	vk := uintptr(hotkey.Key[0])
+	if n := functionKey(hotkey.Key); n > 0 {
+		vk = uintptr(0x70 + n - 1)
+	}
+
+	started := make(chan error, 1)
+	var threadID uintptr
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		threadID, _, _ = procGetCurrentThreadId.Call()
+		if ret, _, err := procRegisterHotKey.Call(0, hotkeyID, mods, vk); ret == 0 {
+			started <- fmt.Errorf("注册快捷键 %s 失败: %v", hotkey, err)
+			return
+		}
+		defer procUnregisterHotKey.Call(0, hotkeyID)
+		started <- nil
+
+		var msg struct {
+			Hwnd    uintptr
+			Message uint32
+			WParam  uintptr
+			LParam  uintptr
+			Time    uint32
+			Pt      struct{ X, Y int32 }
+		}
+		for {
+			// 收到 WM_QUIT 返回 0，出错返回 -1
+			ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+			if int32(ret) <= 0 {
+				return
+			}
+			if msg.Message == WM_HOTKEY {
+				go fn()
+			}
+		}
+	}()
+
+	if err := <-started; err != nil {
+		return nil, err
+	}
+	return func() {
+		procPostThreadMessageW.Call(threadID, WM_QUIT, 0, 0)
+	}, nil
+}
+
+// findWorkerW 查找包含桌面图标（SHELLDLL_DefView）的 WorkerW 窗口，找不到时返回 0
+func findWorkerW() uintptr {
+	enumMu.Lock()
+	defer enumMu.Unlock()
+	enumWorkerW = 0
+	// 枚举所有顶级窗口
+	procEnumWindows.Call(enumWorkerCallback, 0)
+	return enumWorkerW
+}
+
+// enumWorker EnumWindows 的回调，找到 WorkerW 窗口后停止枚举
+func enumWorker(hwnd, lParam uintptr) uintptr {
+	// 获取窗口类名
+	classBuf := make([]uint16, 256)
+	procGetClassNameW.Call(hwnd, uintptr(unsafe.Pointer(&classBuf[0])), 256)
+	className := syscall.UTF16ToString(classBuf)
+
+	// 查找 WorkerW 窗口，并检查它是否有 SHELLDLL_DefView 子窗口
+	if className == "WorkerW" {
+		shellView, _, _ := procFindWindowExW.Call(hwnd, 0,
+			uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr("SHELLDLL_DefView"))), 0)
+		if shellView != 0 {
+			// 找到了包含桌面图标的 WorkerW 窗口
+			enumWorkerW = hwnd
+			return 0 // 停止枚举
+		}
+	}
+	return 1 // 继续枚举
+}
+
 // 设置为桌面子窗口 - 抵抗显示桌面，融入桌面环境
-func SetupDesktopChildWidget() error {
+func setupDesktopChildWidget() error {
 	// 1. 查找我们的窗口
 	hwnd, _, _ := procFindWindowW.Call(
 		0,
@@ -54,28 +392,7 @@ func SetupDesktopChildWidget() error {
 	procSendMessage.Call(progmanHwnd, 0x052C, 0x0000000D, 1)
 
 	// 3. 查找新创建的 WorkerW 窗口
-	var workerHwnd uintptr
-	enumCallback := syscall.NewCallback(func(hwnd, lParam uintptr) uintptr {
-		// 获取窗口类名
-		classBuf := make([]uint16, 256)
-		procGetClassNameW.Call(hwnd, uintptr(unsafe.Pointer(&classBuf[0])), 256)
-		className := syscall.UTF16ToString(classBuf)
-
-		// 查找 WorkerW 窗口，并检查它是否有 SHELLDLL_DefView 子窗口
-		if className == "WorkerW" {
-			shellView, _, _ := procFindWindowExW.Call(hwnd, 0,
-				uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr("SHELLDLL_DefView"))), 0)
-			if shellView != 0 {
-				// 找到了包含桌面图标的 WorkerW 窗口
-				workerHwnd = hwnd
-				return 0 // 停止枚举
-			}
-		}
-		return 1 // 继续枚举
-	})
-
-	// 枚举所有顶级窗口
-	procEnumWindows.Call(enumCallback, 0)
+	workerHwnd := findWorkerW()
 
 	// 4. 如果找到了 WorkerW 窗口，将我们的窗口设为其子窗口
 	var parentHwnd uintptr
@@ -88,20 +405,8 @@ func SetupDesktopChildWidget() error {
 		log.Println("使用 Progman 作为父窗口")
 	}
 
-	// 5. 设置窗口样式（在设置父窗口之前）
-	gwlExstyle := uintptr(^uint32(19)) // -20
-	exStyle, _, _ := procGetWindowLongW.Call(hwnd, gwlExstyle)
-
-	const (
-		WS_EX_TOOLWINDOW = 0x00000080 // 不在 Alt+Tab 显示
-		WS_EX_LAYERED    = 0x00080000 // 支持透明
-		WS_EX_NOACTIVATE = 0x08000000 // 不激活窗口
-		WS_EX_APPWINDOW  = 0x00040000 // 在任务栏显示（要移除）
-	)
-
-	// 设置桌面小部件样式
-	newStyle := (exStyle | WS_EX_TOOLWINDOW | WS_EX_LAYERED | WS_EX_NOACTIVATE) &^ WS_EX_APPWINDOW
-	procSetWindowLongW.Call(hwnd, gwlExstyle, newStyle)
+	// 5. 设置桌面小部件样式（在设置父窗口之前）
+	setExStyle(hwnd, WS_EX_TOOLWINDOW|WS_EX_LAYERED|WS_EX_NOACTIVATE, WS_EX_APPWINDOW)
 
 	// 6. 设置为桌面的子窗口（关键步骤）
 	result, _, _ := procSetParent.Call(hwnd, parentHwnd)
@@ -109,19 +414,7 @@ func SetupDesktopChildWidget() error {
 		return fmt.Errorf("设置父窗口失败")
 	}
 
-	// 7. 设置透明度
-	// procSetLayeredWindowAttributes.Call(hwnd, 0, 230, 0x02) // 稍微透明
-
-	// 8. 最终位置设置 - 不需要设置 TOPMOST，父子关系会处理层级
-	const (
-		SWP_NOMOVE       = 0x0002
-		SWP_NOSIZE       = 0x0001
-		SWP_NOZORDER     = 0x0004 // 保持 Z 顺序由父子关系决定
-		SWP_NOACTIVATE   = 0x0010
-		SWP_FRAMECHANGED = 0x0020
-		SWP_SHOWWINDOW   = 0x0040
-	)
-
+	// 7. 最终位置设置 - 不需要设置 TOPMOST，父子关系会处理层级，保持 Z 顺序由父子关系决定
 	procSetWindowPos.Call(
 		hwnd,
 		0, // 不指定插入位置
@@ -132,7 +425,138 @@ func SetupDesktopChildWidget() error {
 	return nil
 }
 
-func SetTransparentBackground() {
-	hwnd := win.FindWindow(nil, syscall.StringToUTF16Ptr(window_title))
-	win.SetWindowLong(hwnd, win.GWL_EXSTYLE, win.GetWindowLong(hwnd, win.GWL_EXSTYLE)|win.WS_EX_LAYERED)
+type rect struct {
+	Left, Top, Right, Bottom int32
+}
+
+func (r rect) toRect() Rect {
+	return Rect{X: int(r.Left), Y: int(r.Top), Width: int(r.Right - r.Left), Height: int(r.Bottom - r.Top)}
+}
+
+// monitorInfoEx MONITORINFOEXW
+type monitorInfoEx struct {
+	CbSize    uint32
+	RcMonitor rect
+	RcWork    rect
+	DwFlags   uint32
+	SzDevice  [32]uint16
+}
+
+func findWindow() (uintptr, error) {
+	hwnd, _, _ := procFindWindowW.Call(
+		0,
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(window_title))),
+	)
+	if hwnd == 0 {
+		return 0, fmt.Errorf("找不到窗口: %s", window_title)
+	}
+	return hwnd, nil
+}
+
+func (d *windowsDesktop) Monitors() ([]Monitor, error) {
+	const MONITOR_DEFAULTTONEAREST = 0x2
+
+	var current uintptr
+	if hwnd, err := findWindow(); err == nil {
+		current, _, _ = procMonitorFromWindow.Call(hwnd, MONITOR_DEFAULTTONEAREST)
+	}
+
+	enumMu.Lock()
+	defer enumMu.Unlock()
+	enumCurrent = current
+	enumMonitors = nil
+	if ret, _, err := procEnumDisplayMonitors.Call(0, 0, enumMonitorCallback, 0); ret == 0 {
+		return nil, fmt.Errorf("获取显示器失败: %v", err)
+	}
+	monitors := enumMonitors
+	enumMonitors = nil
+	return monitors, nil
+}
+
+// enumMonitor EnumDisplayMonitors 的回调，将显示器加入 enumMonitors
+func enumMonitor(hMonitor, hdc, lprc, lParam uintptr) uintptr {
+	const (
+		MONITORINFOF_PRIMARY = 0x1
+		MDT_EFFECTIVE_DPI    = 0
+	)
+
+	info := monitorInfoEx{}
+	info.CbSize = uint32(unsafe.Sizeof(info))
+	if ret, _, _ := procGetMonitorInfoW.Call(hMonitor, uintptr(unsafe.Pointer(&info))); ret == 0 {
+		return 1
+	}
+
+	// Windows 8.1 之前没有 GetDpiForMonitor，按 96 DPI 处理
+	scale := 1.0
+	if procGetDpiForMonitor.Find() == nil {
+		var dpiX, dpiY uint32
+		hr, _, _ := procGetDpiForMonitor.Call(hMonitor, MDT_EFFECTIVE_DPI,
+			uintptr(unsafe.Pointer(&dpiX)), uintptr(unsafe.Pointer(&dpiY)))
+		if hr == 0 && dpiX > 0 {
+			scale = float64(dpiX) / 96
+		}
+	}
+
+	enumMonitors = append(enumMonitors, Monitor{
+		ID:       syscall.UTF16ToString(info.SzDevice[:]),
+		Primary:  info.DwFlags&MONITORINFOF_PRIMARY != 0,
+		Current:  hMonitor == enumCurrent,
+		Bounds:   info.RcMonitor.toRect(),
+		WorkArea: info.RcWork.toRect(),
+		Scale:    scale,
+	})
+	return 1
+}
+
+func (d *windowsDesktop) WindowBounds() (Rect, error) {
+	hwnd, err := findWindow()
+	if err != nil {
+		return Rect{}, err
+	}
+
+	var r rect
+	if ret, _, err := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&r))); ret == 0 {
+		return Rect{}, fmt.Errorf("获取窗口位置失败: %v", err)
+	}
+	return r.toRect(), nil
+}
+
+// toParent 将屏幕坐标转换为窗口的父窗口坐标。
+// 桌面小部件模式下窗口是 WorkerW 的子窗口，SetWindowPos 使用父窗口的坐标
+func toParent(hwnd uintptr, x, y int) (int, int) {
+	parent, _, _ := procGetParent.Call(hwnd)
+	if parent == 0 {
+		return x, y
+	}
+	pt := struct{ X, Y int32 }{int32(x), int32(y)}
+	procMapWindowPoints.Call(0, parent, uintptr(unsafe.Pointer(&pt)), 1)
+	return int(pt.X), int(pt.Y)
+}
+
+func (d *windowsDesktop) SetWindowPosition(x, y int) error {
+	hwnd, err := findWindow()
+	if err != nil {
+		return err
+	}
+
+	x, y = toParent(hwnd, x, y)
+	if ret, _, err := procSetWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y), 0, 0,
+		uintptr(SWP_NOSIZE|SWP_NOZORDER|SWP_NOACTIVATE)); ret == 0 {
+		return fmt.Errorf("移动窗口失败: %v", err)
+	}
+	return nil
+}
+
+func (d *windowsDesktop) SetWindowBounds(bounds Rect) error {
+	hwnd, err := findWindow()
+	if err != nil {
+		return err
+	}
+
+	x, y := toParent(hwnd, bounds.X, bounds.Y)
+	if ret, _, err := procSetWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y),
+		uintptr(bounds.Width), uintptr(bounds.Height), uintptr(SWP_NOZORDER|SWP_NOACTIVATE)); ret == 0 {
+		return fmt.Errorf("设置窗口位置失败: %v", err)
+	}
+	return nil
 }
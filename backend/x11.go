@@ -0,0 +1,555 @@
+//go:build linux
+
+package backend
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/randr"
+	"github.com/jezek/xgb/shape"
+	"github.com/jezek/xgb/xproto"
+)
+
+// 所有工作区，见 EWMH _NET_WM_DESKTOP
+const allDesktops = 0xFFFFFFFF
+
+// x11 到 X Server 的连接，按名称缓存 atom
+type x11 struct {
+	conn  *xgb.Conn
+	root  xproto.Window
+	atoms map[string]xproto.Atom
+}
+
+func newX11() (*x11, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("连接 X Server 失败: %v", err)
+	}
+	return &x11{
+		conn:  conn,
+		root:  xproto.Setup(conn).DefaultScreen(conn).Root,
+		atoms: make(map[string]xproto.Atom),
+	}, nil
+}
+
+func (x *x11) close() {
+	x.conn.Close()
+}
+
+func (x *x11) atom(name string) (xproto.Atom, error) {
+	if a, ok := x.atoms[name]; ok {
+		return a, nil
+	}
+	reply, err := xproto.InternAtom(x.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("获取 atom %s 失败: %v", name, err)
+	}
+	x.atoms[name] = reply.Atom
+	return reply.Atom, nil
+}
+
+// property 读取窗口属性，属性不存在时返回 nil
+func (x *x11) property(win xproto.Window, name string) (*xproto.GetPropertyReply, error) {
+	a, err := x.atom(name)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := xproto.GetProperty(x.conn, false, win, a, xproto.GetPropertyTypeAny, 0, 1<<16).Reply()
+	if err != nil {
+		return nil, err
+	}
+	if reply.Format == 0 {
+		return nil, nil
+	}
+	return reply, nil
+}
+
+// setAtoms 将属性设置为一组 atom
+func (x *x11) setAtoms(win xproto.Window, name string, values ...string) error {
+	prop, err := x.atom(name)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		a, err := x.atom(v)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint32(data[i*4:], uint32(a))
+	}
+	return xproto.ChangePropertyChecked(x.conn, xproto.PropModeReplace, win, prop, xproto.AtomAtom, 32, uint32(len(values)), data).Check()
+}
+
+// setCardinal 将属性设置为一个整数
+func (x *x11) setCardinal(win xproto.Window, name string, value uint32) error {
+	prop, err := x.atom(name)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, value)
+	return xproto.ChangePropertyChecked(x.conn, xproto.PropModeReplace, win, prop, xproto.AtomCardinal, 32, 1, data).Check()
+}
+
+// findWindow 查找当前进程中标题为 title 的顶层窗口。
+// 没有窗口管理器时（例如 Xvfb）没有 _NET_CLIENT_LIST，因此遍历整个窗口树
+func (x *x11) findWindow(title string) (xproto.Window, error) {
+	pid := uint32(os.Getpid())
+
+	var search func(win xproto.Window) (xproto.Window, bool)
+	search = func(win xproto.Window) (xproto.Window, bool) {
+		if x.windowPID(win) == pid && x.windowTitle(win) == title {
+			return win, true
+		}
+		tree, err := xproto.QueryTree(x.conn, win).Reply()
+		if err != nil {
+			return 0, false
+		}
+		for _, child := range tree.Children {
+			if found, ok := search(child); ok {
+				return found, true
+			}
+		}
+		return 0, false
+	}
+
+	if win, ok := search(x.root); ok {
+		return win, nil
+	}
+	return 0, fmt.Errorf("找不到窗口: %s", title)
+}
+
+func (x *x11) windowPID(win xproto.Window) uint32 {
+	reply, err := x.property(win, "_NET_WM_PID")
+	if err != nil || reply == nil || len(reply.Value) < 4 {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(reply.Value)
+}
+
+func (x *x11) windowTitle(win xproto.Window) string {
+	for _, name := range []string{"_NET_WM_NAME", "WM_NAME"} {
+		reply, err := x.property(win, name)
+		if err == nil && reply != nil && len(reply.Value) > 0 {
+			return string(reply.Value)
+		}
+	}
+	return ""
+}
+
+// waitWithdrawn 等待窗口管理器处理完取消映射。没有窗口管理器时 WM_STATE 不存在，直接返回
+func (x *x11) waitWithdrawn(win xproto.Window) {
+	for i := 0; i < 50; i++ {
+		reply, err := x.property(win, "WM_STATE")
+		if err != nil || reply == nil || len(reply.Value) < 4 || binary.LittleEndian.Uint32(reply.Value) == 0 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// setupDesktopWidget 将窗口设置为桌面类型：位于其它窗口下方、在所有工作区显示、
+// 不出现在任务栏和窗口切换器中，“显示桌面”也不会隐藏它。
+// 窗口管理器只在映射窗口时读取窗口类型，因此先取消映射，设置属性后再重新映射
+func (x *x11) setupDesktopWidget(win xproto.Window) error {
+	if err := xproto.UnmapWindowChecked(x.conn, win).Check(); err != nil {
+		return fmt.Errorf("取消映射窗口失败: %v", err)
+	}
+	x.waitWithdrawn(win)
+
+	if err := x.setAtoms(win, "_NET_WM_WINDOW_TYPE", "_NET_WM_WINDOW_TYPE_DESKTOP"); err != nil {
+		return fmt.Errorf("设置窗口类型失败: %v", err)
+	}
+	if err := x.setAtoms(win, "_NET_WM_STATE",
+		"_NET_WM_STATE_BELOW",
+		"_NET_WM_STATE_STICKY",
+		"_NET_WM_STATE_SKIP_TASKBAR",
+		"_NET_WM_STATE_SKIP_PAGER",
+	); err != nil {
+		return fmt.Errorf("设置窗口状态失败: %v", err)
+	}
+	if err := x.setCardinal(win, "_NET_WM_DESKTOP", allDesktops); err != nil {
+		return fmt.Errorf("设置工作区失败: %v", err)
+	}
+
+	if err := xproto.MapWindowChecked(x.conn, win).Check(); err != nil {
+		return fmt.Errorf("映射窗口失败: %v", err)
+	}
+	return nil
+}
+
+// savedProperties 进入小部件模式前的窗口属性，值为 nil 表示属性不存在
+type savedProperties map[string]*xproto.GetPropertyReply
+
+// widgetProperties 小部件模式修改、退出时需要恢复的属性
+var widgetProperties = []string{"_NET_WM_WINDOW_TYPE", "_NET_WM_STATE"}
+
+func (x *x11) saveProperties(win xproto.Window, names ...string) (savedProperties, error) {
+	saved := make(savedProperties, len(names))
+	for _, name := range names {
+		reply, err := x.property(win, name)
+		if err != nil {
+			return nil, fmt.Errorf("读取窗口属性 %s 失败: %v", name, err)
+		}
+		saved[name] = reply
+	}
+	return saved, nil
+}
+
+// restoreWidget 恢复进入小部件模式前的窗口类型和状态。
+// 不恢复 _NET_WM_DESKTOP，由窗口管理器将窗口放到当前工作区
+func (x *x11) restoreWidget(win xproto.Window, saved savedProperties) error {
+	if err := xproto.UnmapWindowChecked(x.conn, win).Check(); err != nil {
+		return fmt.Errorf("取消映射窗口失败: %v", err)
+	}
+	x.waitWithdrawn(win)
+
+	names := append([]string{"_NET_WM_DESKTOP"}, widgetProperties...)
+	for _, name := range names {
+		prop, err := x.atom(name)
+		if err != nil {
+			return err
+		}
+		if reply := saved[name]; reply != nil {
+			err = xproto.ChangePropertyChecked(x.conn, xproto.PropModeReplace, win, prop, reply.Type, reply.Format, reply.ValueLen, reply.Value).Check()
+		} else {
+			err = xproto.DeletePropertyChecked(x.conn, win, prop).Check()
+		}
+		if err != nil {
+			return fmt.Errorf("恢复窗口属性 %s 失败: %v", name, err)
+		}
+	}
+
+	if err := xproto.MapWindowChecked(x.conn, win).Check(); err != nil {
+		return fmt.Errorf("映射窗口失败: %v", err)
+	}
+	return nil
+}
+
+// setOpacity 设置 _NET_WM_WINDOW_OPACITY，由混成管理器实现透明效果
+func (x *x11) setOpacity(win xproto.Window, opacity float64) error {
+	if err := x.setCardinal(win, "_NET_WM_WINDOW_OPACITY", uint32(opacity*0xFFFFFFFF)); err != nil {
+		return fmt.Errorf("设置透明度失败: %v", err)
+	}
+	return nil
+}
+
+// setClickThrough 通过 Shape 扩展将窗口的输入区域设置为空，鼠标事件会穿透到下方窗口
+func (x *x11) setClickThrough(win xproto.Window, enabled bool) error {
+	if err := shape.Init(x.conn); err != nil {
+		return fmt.Errorf("X Server 不支持 Shape 扩展: %v", err)
+	}
+	var err error
+	if enabled {
+		err = shape.RectanglesChecked(x.conn, shape.SoSet, shape.SkInput, xproto.ClipOrderingUnsorted, win, 0, 0, nil).Check()
+	} else {
+		// 输入区域设置为 None 时恢复为整个窗口
+		err = shape.MaskChecked(x.conn, shape.SoSet, shape.SkInput, win, 0, 0, xproto.PixmapNone).Check()
+	}
+	if err != nil {
+		return fmt.Errorf("设置鼠标穿透失败: %v", err)
+	}
+	return nil
+}
+
+// setBelow 请求窗口管理器添加或移除 _NET_WM_STATE_BELOW
+func (x *x11) setBelow(win xproto.Window, enabled bool) error {
+	state, err := x.atom("_NET_WM_STATE")
+	if err != nil {
+		return err
+	}
+	below, err := x.atom("_NET_WM_STATE_BELOW")
+	if err != nil {
+		return err
+	}
+
+	const (
+		stateRemove = 0
+		stateAdd    = 1
+		sourceApp   = 1
+	)
+	action := uint32(stateRemove)
+	if enabled {
+		action = stateAdd
+	}
+	event := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: win,
+		Type:   state,
+		Data:   xproto.ClientMessageDataUnionData32New([]uint32{action, uint32(below), 0, sourceApp, 0}),
+	}
+	mask := uint32(xproto.EventMaskSubstructureRedirect | xproto.EventMaskSubstructureNotify)
+	if err := xproto.SendEventChecked(x.conn, false, x.root, mask, string(event.Bytes())).Check(); err != nil {
+		return fmt.Errorf("设置窗口层级失败: %v", err)
+	}
+	return nil
+}
+
+// monitors 通过 RandR 获取已连接的显示器，ID 为输出名称（例如 HDMI-1）。
+// X11 没有每个显示器单独的工作区域和缩放比例：工作区域取 _NET_WORKAREA 与显示器的交集，
+// 缩放比例取 Xft.dpi
+func (x *x11) monitors() ([]Monitor, error) {
+	if err := randr.Init(x.conn); err != nil {
+		return nil, fmt.Errorf("X Server 不支持 RandR 扩展: %v", err)
+	}
+	res, err := randr.GetScreenResourcesCurrent(x.conn, x.root).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("获取显示器失败: %v", err)
+	}
+	var primary randr.Output
+	if reply, err := randr.GetOutputPrimary(x.conn, x.root).Reply(); err == nil {
+		primary = reply.Output
+	}
+
+	workArea, hasWorkArea := x.workArea()
+	scale := x.scale()
+
+	var monitors []Monitor
+	// 镜像显示的多个输出共用一个 CRTC，只保留一个
+	crtcs := make(map[randr.Crtc]int)
+	for _, output := range res.Outputs {
+		info, err := randr.GetOutputInfo(x.conn, output, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return nil, fmt.Errorf("获取显示器 %d 失败: %v", output, err)
+		}
+		if info.Connection != randr.ConnectionConnected || info.Crtc == 0 {
+			continue
+		}
+		if i, ok := crtcs[info.Crtc]; ok {
+			if output == primary {
+				monitors[i].ID = string(info.Name)
+				monitors[i].Primary = true
+			}
+			continue
+		}
+
+		crtc, err := randr.GetCrtcInfo(x.conn, info.Crtc, res.ConfigTimestamp).Reply()
+		if err != nil {
+			return nil, fmt.Errorf("获取显示器 %s 失败: %v", info.Name, err)
+		}
+		bounds := Rect{X: int(crtc.X), Y: int(crtc.Y), Width: int(crtc.Width), Height: int(crtc.Height)}
+		area := bounds
+		if hasWorkArea {
+			area = clipRect(bounds, workArea)
+		}
+		crtcs[info.Crtc] = len(monitors)
+		monitors = append(monitors, Monitor{
+			ID:       string(info.Name),
+			Primary:  output == primary,
+			Bounds:   bounds,
+			WorkArea: area,
+			Scale:    scale,
+		})
+	}
+	if len(monitors) == 0 {
+		return nil, fmt.Errorf("没有已连接的显示器")
+	}
+	return monitors, nil
+}
+
+// workArea 读取当前工作区的 _NET_WORKAREA，没有窗口管理器时返回 false
+func (x *x11) workArea() (Rect, bool) {
+	reply, err := x.property(x.root, "_NET_WORKAREA")
+	if err != nil || reply == nil || len(reply.Value) < 16 {
+		return Rect{}, false
+	}
+	desktop := 0
+	if current, err := x.property(x.root, "_NET_CURRENT_DESKTOP"); err == nil && current != nil && len(current.Value) >= 4 {
+		desktop = int(binary.LittleEndian.Uint32(current.Value))
+	}
+	if (desktop+1)*16 > len(reply.Value) {
+		desktop = 0
+	}
+	v := reply.Value[desktop*16:]
+	return Rect{
+		X:      int(int32(binary.LittleEndian.Uint32(v[0:]))),
+		Y:      int(int32(binary.LittleEndian.Uint32(v[4:]))),
+		Width:  int(binary.LittleEndian.Uint32(v[8:])),
+		Height: int(binary.LittleEndian.Uint32(v[12:])),
+	}, true
+}
+
+// scale 读取根窗口 RESOURCE_MANAGER 中的 Xft.dpi，没有设置时为 1
+func (x *x11) scale() float64 {
+	reply, err := x.property(x.root, "RESOURCE_MANAGER")
+	if err != nil || reply == nil {
+		return 1
+	}
+	return xftScale(string(reply.Value))
+}
+
+// xftScale 从 X 资源中解析 Xft.dpi，换算为相对 96 DPI 的缩放比例
+func xftScale(resources string) float64 {
+	for _, line := range strings.Split(resources, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) != "Xft.dpi" {
+			continue
+		}
+		if dpi, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && dpi > 0 {
+			return dpi / 96
+		}
+	}
+	return 1
+}
+
+// intersect 返回两个矩形的交集，没有交集时返回 false
+func intersect(a, b Rect) (Rect, bool) {
+	left, top := max(a.X, b.X), max(a.Y, b.Y)
+	right, bottom := min(a.X+a.Width, b.X+b.Width), min(a.Y+a.Height, b.Y+b.Height)
+	if right <= left || bottom <= top {
+		return Rect{}, false
+	}
+	return Rect{X: left, Y: top, Width: right - left, Height: bottom - top}, true
+}
+
+// clipRect 返回 r 在 clip 内的部分，没有交集时返回 r
+func clipRect(r, clip Rect) Rect {
+	if clipped, ok := intersect(r, clip); ok {
+		return clipped
+	}
+	return r
+}
+
+// markCurrent 将与窗口重叠面积最大的显示器标记为当前显示器
+func markCurrent(monitors []Monitor, window Rect) {
+	best, bestArea := -1, 0
+	for i, m := range monitors {
+		if r, ok := intersect(window, m.Bounds); ok && r.Width*r.Height > bestArea {
+			best, bestArea = i, r.Width*r.Height
+		}
+	}
+	if best >= 0 {
+		monitors[best].Current = true
+	}
+}
+
+// frameExtents 窗口管理器在窗口四周添加的边框，见 EWMH _NET_FRAME_EXTENTS
+type frameExtents struct {
+	Left, Right, Top, Bottom int
+}
+
+func (x *x11) frameExtents(win xproto.Window) frameExtents {
+	reply, err := x.property(win, "_NET_FRAME_EXTENTS")
+	if err != nil || reply == nil || len(reply.Value) < 16 {
+		return frameExtents{}
+	}
+	v := reply.Value
+	return frameExtents{
+		Left:   int(binary.LittleEndian.Uint32(v[0:])),
+		Right:  int(binary.LittleEndian.Uint32(v[4:])),
+		Top:    int(binary.LittleEndian.Uint32(v[8:])),
+		Bottom: int(binary.LittleEndian.Uint32(v[12:])),
+	}
+}
+
+// windowBounds 窗口在根窗口坐标中的位置和大小，包含窗口管理器添加的边框
+func (x *x11) windowBounds(win xproto.Window) (Rect, error) {
+	geometry, err := xproto.GetGeometry(x.conn, xproto.Drawable(win)).Reply()
+	if err != nil {
+		return Rect{}, fmt.Errorf("获取窗口大小失败: %v", err)
+	}
+	origin, err := xproto.TranslateCoordinates(x.conn, win, x.root, 0, 0).Reply()
+	if err != nil {
+		return Rect{}, fmt.Errorf("获取窗口位置失败: %v", err)
+	}
+	ext := x.frameExtents(win)
+	return Rect{
+		X:      int(origin.DstX) - ext.Left,
+		Y:      int(origin.DstY) - ext.Top,
+		Width:  int(geometry.Width) + ext.Left + ext.Right,
+		Height: int(geometry.Height) + ext.Top + ext.Bottom,
+	}, nil
+}
+
+// setWindowBounds 移动窗口，bounds 包含边框，宽或高为 0 时只移动不改变大小。
+// 窗口管理器按 ICCCM 的 NorthWest 重力将边框左上角放到 (X, Y)
+func (x *x11) setWindowBounds(win xproto.Window, bounds Rect) error {
+	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY)
+	values := []uint32{uint32(int32(bounds.X)), uint32(int32(bounds.Y))}
+	if bounds.Width > 0 && bounds.Height > 0 {
+		ext := x.frameExtents(win)
+		mask |= xproto.ConfigWindowWidth | xproto.ConfigWindowHeight
+		values = append(values,
+			uint32(max(bounds.Width-ext.Left-ext.Right, 1)),
+			uint32(max(bounds.Height-ext.Top-ext.Bottom, 1)))
+	}
+	if err := xproto.ConfigureWindowChecked(x.conn, win, mask, values).Check(); err != nil {
+		return fmt.Errorf("移动窗口失败: %v", err)
+	}
+	return nil
+}
+
+// keycode 查找产生 keysym 的键码
+func (x *x11) keycode(keysym xproto.Keysym) (xproto.Keycode, error) {
+	setup := xproto.Setup(x.conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	mapping, err := xproto.GetKeyboardMapping(x.conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("获取键盘映射失败: %v", err)
+	}
+	per := int(mapping.KeysymsPerKeycode)
+	for i := 0; i < int(count); i++ {
+		for j := 0; j < per; j++ {
+			if mapping.Keysyms[i*per+j] == keysym {
+				return setup.MinKeycode + xproto.Keycode(i), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("键盘上没有该按键")
+}
+
+// keysym 快捷键主键对应的 keysym，字母使用小写
+func keysym(key string) xproto.Keysym {
+	if n := functionKey(key); n > 0 {
+		return xproto.Keysym(0xFFBE + n - 1)
+	}
+	c := key[0]
+	if c >= 'A' && c <= 'Z' {
+		c += 'a' - 'A'
+	}
+	return xproto.Keysym(c)
+}
+
+// grabHotkey 在根窗口上抓取快捷键，关闭连接时自动释放。
+// NumLock、CapsLock 打开时修饰键不同，需要分别抓取
+func (x *x11) grabHotkey(hotkey Hotkey) (xproto.Keycode, error) {
+	code, err := x.keycode(keysym(hotkey.Key))
+	if err != nil {
+		return 0, err
+	}
+
+	var mods uint16
+	if hotkey.Ctrl {
+		mods |= xproto.ModMaskControl
+	}
+	if hotkey.Alt {
+		mods |= xproto.ModMask1
+	}
+	if hotkey.Shift {
+		mods |= xproto.ModMaskShift
+	}
+	if hotkey.Super {
+		mods |= xproto.ModMask4
+	}
+
+	var grabbed []uint16
+	for _, extra := range []uint16{0, xproto.ModMaskLock, xproto.ModMask2, xproto.ModMaskLock | xproto.ModMask2} {
+		err := xproto.GrabKeyChecked(x.conn, true, x.root, mods|extra, code,
+			xproto.GrabModeAsync, xproto.GrabModeAsync).Check()
+		if err != nil {
+			for _, m := range grabbed {
+				xproto.UngrabKey(x.conn, code, x.root, m)
+			}
+			return 0, fmt.Errorf("快捷键已被其它程序占用: %v", err)
+		}
+		grabbed = append(grabbed, mods|extra)
+	}
+	return code, nil
+}
@@ -0,0 +1,184 @@
+//go:build linux
+
+package backend
+
+import (
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+// newTestWindow 创建并映射一个带标题和 _NET_WM_PID 的顶层窗口，没有 X Server 时跳过测试。
+// 可以在 Xvfb 中运行：xvfb-run go test ./backend
+func newTestWindow(t *testing.T, title string) (*x11, xproto.Window) {
+	t.Helper()
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY is not set")
+	}
+	x, err := newX11()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(x.close)
+
+	win, err := xproto.NewWindowId(x.conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := xproto.CreateWindowChecked(x.conn, 0, win, x.root, 0, 0, 200, 100, 0,
+		xproto.WindowClassInputOutput, 0, 0, nil).Check(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { xproto.DestroyWindow(x.conn, win) })
+
+	if err := xproto.ChangePropertyChecked(x.conn, xproto.PropModeReplace, win, xproto.AtomWmName,
+		xproto.AtomString, 8, uint32(len(title)), []byte(title)).Check(); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.setCardinal(win, "_NET_WM_PID", uint32(os.Getpid())); err != nil {
+		t.Fatal(err)
+	}
+	if err := xproto.MapWindowChecked(x.conn, win).Check(); err != nil {
+		t.Fatal(err)
+	}
+	return x, win
+}
+
+// atomNames 读取属性中的 atom 名称，属性不存在时返回 nil
+func (x *x11) atomNames(t *testing.T, win xproto.Window, name string) []string {
+	t.Helper()
+	reply, err := x.property(win, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply == nil {
+		return nil
+	}
+	var names []string
+	for i := 0; i+4 <= len(reply.Value); i += 4 {
+		a := xproto.Atom(binary.LittleEndian.Uint32(reply.Value[i:]))
+		atomName, err := xproto.GetAtomName(x.conn, a).Reply()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, atomName.Name)
+	}
+	return names
+}
+
+// 进入小部件模式后窗口为桌面类型，退出后恢复原来的窗口类型和状态
+func TestX11DesktopWidget(t *testing.T) {
+	x, win := newTestWindow(t, "zeeho-widgets-test")
+	if err := x.setAtoms(win, "_NET_WM_STATE", "_NET_WM_STATE_ABOVE"); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := x.findWindow("zeeho-widgets-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != win {
+		t.Fatalf("findWindow = %d, want %d", found, win)
+	}
+
+	saved, err := x.saveProperties(win, append([]string{"_NET_WM_DESKTOP"}, widgetProperties...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := x.setupDesktopWidget(win); err != nil {
+		t.Fatal(err)
+	}
+	if got := x.atomNames(t, win, "_NET_WM_WINDOW_TYPE"); !reflect.DeepEqual(got, []string{"_NET_WM_WINDOW_TYPE_DESKTOP"}) {
+		t.Errorf("_NET_WM_WINDOW_TYPE = %v", got)
+	}
+	wantState := []string{"_NET_WM_STATE_BELOW", "_NET_WM_STATE_STICKY", "_NET_WM_STATE_SKIP_TASKBAR", "_NET_WM_STATE_SKIP_PAGER"}
+	if got := x.atomNames(t, win, "_NET_WM_STATE"); !reflect.DeepEqual(got, wantState) {
+		t.Errorf("_NET_WM_STATE = %v, want %v", got, wantState)
+	}
+
+	if err := x.restoreWidget(win, saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := x.atomNames(t, win, "_NET_WM_WINDOW_TYPE"); got != nil {
+		t.Errorf("_NET_WM_WINDOW_TYPE should be removed, got %v", got)
+	}
+	if got := x.atomNames(t, win, "_NET_WM_STATE"); !reflect.DeepEqual(got, []string{"_NET_WM_STATE_ABOVE"}) {
+		t.Errorf("_NET_WM_STATE = %v, want the original state", got)
+	}
+	if reply, err := x.property(win, "_NET_WM_DESKTOP"); err != nil || reply != nil {
+		t.Errorf("_NET_WM_DESKTOP should be removed: %v %v", reply, err)
+	}
+}
+
+func TestXftScale(t *testing.T) {
+	for resources, want := range map[string]float64{
+		"Xft.dpi:\t192\nXft.antialias:\t1\n": 2,
+		"Xcursor.size: 24\nXft.dpi: 144":     1.5,
+		"Xft.dpi:\tabc\n":                    1,
+		"":                                   1,
+	} {
+		if got := xftScale(resources); got != want {
+			t.Errorf("xftScale(%q) = %v, want %v", resources, got, want)
+		}
+	}
+}
+
+// 工作区域跨越全部显示器，每个显示器取其中属于自己的部分；
+// 窗口跨越两个显示器时，重叠面积大的为当前显示器
+func TestMonitorGeometry(t *testing.T) {
+	left := Rect{Width: 1920, Height: 1080}
+	right := Rect{X: 1920, Width: 2560, Height: 1440}
+	workArea := Rect{Y: 32, Width: 4480, Height: 1408}
+
+	if got, want := clipRect(left, workArea), (Rect{Y: 32, Width: 1920, Height: 1048}); got != want {
+		t.Errorf("left work area = %+v, want %+v", got, want)
+	}
+	if got, want := clipRect(right, workArea), (Rect{X: 1920, Y: 32, Width: 2560, Height: 1408}); got != want {
+		t.Errorf("right work area = %+v, want %+v", got, want)
+	}
+	if got := clipRect(left, Rect{X: 5000, Width: 10, Height: 10}); got != left {
+		t.Errorf("disjoint work area should be ignored, got %+v", got)
+	}
+
+	monitors := []Monitor{{ID: "left", Bounds: left}, {ID: "right", Bounds: right}}
+	markCurrent(monitors, Rect{X: 1800, Y: 100, Width: 300, Height: 200})
+	if monitors[0].Current || !monitors[1].Current {
+		t.Errorf("current = %+v", monitors)
+	}
+
+	monitors = []Monitor{{ID: "left", Bounds: left}, {ID: "right", Bounds: right}}
+	markCurrent(monitors, Rect{X: 9000, Y: 100, Width: 300, Height: 200})
+	if monitors[0].Current || monitors[1].Current {
+		t.Errorf("window outside every monitor: %+v", monitors)
+	}
+}
+
+// RandR 能获取到显示器，窗口可以移动到指定的屏幕坐标
+func TestX11Placement(t *testing.T) {
+	x, win := newTestWindow(t, "zeeho-widgets-test")
+
+	monitors, err := x.monitors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range monitors {
+		if m.ID == "" || m.Bounds.Width <= 0 || m.WorkArea.Width <= 0 || m.Scale <= 0 {
+			t.Errorf("invalid monitor %+v", m)
+		}
+	}
+
+	want := Rect{X: monitors[0].Bounds.X + 40, Y: monitors[0].Bounds.Y + 30, Width: 240, Height: 120}
+	if err := x.setWindowBounds(win, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := x.windowBounds(win)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("windowBounds = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/bestk/zeeho-widgets/internal/fsutil"
+	"github.com/bestk/zeeho-widgets/secrets"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 备份文件格式
+const (
+	backupFormat  = "zeeho-widgets-backup"
+	backupVersion = 1
+)
+
+// 备份包中的文件
+const (
+	backupManifestFile = "manifest.json"
+	backupConfigFile   = "config.json"
+	backupSecretsFile  = "secrets.json"
+	backupCacheFile    = "cache.json"
+)
+
+// 加密 Token 时使用的附加校验数据
+var backupSecretsAAD = []byte(backupFormat + "/" + backupSecretsFile)
+
+// backupManifest 备份包的描述信息，Files 记录每个文件的 SHA-256，恢复时逐个校验
+type backupManifest struct {
+	Format        string            `json:"format"`
+	Version       int               `json:"version"`
+	ConfigVersion int               `json:"configVersion"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	Files         map[string]string `json:"files"`
+}
+
+// BackupResult 导入备份的结果
+type BackupResult struct {
+	Profiles       int  `json:"profiles"`       // 恢复的账号数量
+	TokensRestored bool `json:"tokensRestored"` // 是否恢复了 Token
+	CacheRestored  bool `json:"cacheRestored"`  // 是否恢复了车辆数据缓存
+	// MissingTokens 没有恢复 Token、本机也没有保存的账号，需要重新填写 Token
+	MissingTokens []string `json:"missingTokens"`
+}
+
+// ExportBackup 将配置、车辆数据缓存打包导出到用户选择的文件。
+// passphrase 不为空时 Token 使用该密码加密后一起导出，否则不包含 Token。
+// 返回保存的路径，用户取消时为空
+func (a *App) ExportBackup(passphrase string) (string, error) {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出备份",
+		DefaultFilename: fmt.Sprintf("zeeho-backup-%s.zip", time.Now().Format("20060102")),
+		Filters: []runtime.FileFilter{
+			{DisplayName: "备份文件 (*.zip)", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("打开文件失败: %v", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := a.exportBackup(passphrase)
+	if err != nil {
+		return "", err
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0600); err != nil {
+		return "", fmt.Errorf("保存备份失败: %v", err)
+	}
+	return path, nil
+}
+
+// ImportBackup 从用户选择的备份文件恢复配置和车辆数据缓存，
+// 备份包含加密的 Token 时需要提供导出时的密码。用户取消时返回 nil
+func (a *App) ImportBackup(passphrase string) (*BackupResult, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入备份",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "备份文件 (*.zip)", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取备份失败: %v", err)
+	}
+	return a.importBackup(data, passphrase)
+}
+
+// exportBackup 生成备份包
+func (a *App) exportBackup(passphrase string) ([]byte, error) {
+	config := a.store.Config()
+
+	files := make(map[string][]byte)
+
+	fileConfig := config.clone()
+	fileConfig.Version = configVersion
+	// Token 只以加密形式保存在 secrets.json 中
+	for i := range fileConfig.Profiles {
+		fileConfig.Profiles[i].Token = ""
+	}
+	data, err := json.MarshalIndent(fileConfig, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[backupConfigFile] = data
+
+	if passphrase != "" {
+		tokens := make(map[string]string)
+		for _, p := range config.Profiles {
+			if p.TokenRef == secrets.RefFile && p.Token == "" {
+				return nil, secrets.ErrLocked
+			}
+			if p.Token != "" {
+				tokens[p.Name] = p.Token
+			}
+		}
+		plain, err := json.Marshal(tokens)
+		if err != nil {
+			return nil, err
+		}
+		if files[backupSecretsFile], err = secrets.Seal(passphrase, plain, backupSecretsAAD); err != nil {
+			return nil, fmt.Errorf("加密Token失败: %v", err)
+		}
+	}
+
+	if data, err := os.ReadFile(a.getCachePath()); err == nil {
+		files[backupCacheFile] = data
+	}
+
+	manifest := backupManifest{
+		Format:        backupFormat,
+		Version:       backupVersion,
+		ConfigVersion: configVersion,
+		CreatedAt:     time.Now(),
+		Files:         make(map[string]string, len(files)),
+	}
+	for name, data := range files {
+		sum := sha256.Sum256(data)
+		manifest.Files[name] = hex.EncodeToString(sum[:])
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeZipFile(zw, backupManifestFile, manifestData); err != nil {
+		return nil, err
+	}
+	for name, data := range files {
+		if err := writeZipFile(zw, name, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// importBackup 校验并恢复备份包。全部校验通过后才会修改当前配置
+func (a *App) importBackup(data []byte, passphrase string) (*BackupResult, error) {
+	files, err := readBackup(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := parseConfig(files[backupConfigFile])
+	if err != nil {
+		return nil, fmt.Errorf("备份中的配置无效: %v", err)
+	}
+
+	result := &BackupResult{Profiles: len(config.Profiles)}
+
+	if sealed, ok := files[backupSecretsFile]; ok {
+		if passphrase == "" {
+			return nil, fmt.Errorf("备份包含加密的Token，请输入导出时设置的密码")
+		}
+		plain, err := secrets.Open(passphrase, sealed, backupSecretsAAD)
+		if err != nil {
+			return nil, err
+		}
+		var tokens map[string]string
+		if err := json.Unmarshal(plain, &tokens); err != nil {
+			return nil, fmt.Errorf("备份中的Token格式错误: %v", err)
+		}
+		for i := range config.Profiles {
+			if token, ok := tokens[config.Profiles[i].Name]; ok {
+				config.Profiles[i].Token = token
+				result.TokensRestored = true
+			}
+		}
+	}
+
+	// 没有恢复 Token 的账号沿用本机已保存的 Token
+	current := a.store.Config()
+	var missing []int
+	for i := range config.Profiles {
+		p := &config.Profiles[i]
+		if p.Token != "" {
+			continue
+		}
+		if existing, ok := current.profile(p.Name); ok && (existing.Token != "" || existing.TokenRef != "") {
+			p.Token = existing.Token
+			p.TokenRef = existing.TokenRef
+			continue
+		}
+		missing = append(missing, i)
+	}
+
+	// 备份中的引用指向导出时那台电脑上的密钥，本机没有对应的 Token，
+	// 标记为需要重新填写，这样配置仍然合法，其它设置可以继续保存
+	for _, i := range missing {
+		config.Profiles[i].TokenRef = ""
+		config.Profiles[i].TokenMissing = true
+		result.MissingTokens = append(result.MissingTokens, config.Profiles[i].Name)
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	var vc vehicleCache
+	cache, hasCache := files[backupCacheFile]
+	if hasCache {
+		if err := json.Unmarshal(cache, &vc); err != nil {
+			return nil, fmt.Errorf("备份中的车辆数据无效: %v", err)
+		}
+	}
+
+	if err := a.saveConfig(&config); err != nil {
+		return nil, fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	if hasCache {
+		if err := fsutil.WriteFileAtomic(a.getCachePath(), cache, 0600); err != nil {
+			return nil, fmt.Errorf("恢复车辆数据失败: %v", err)
+		}
+		a.store.SetVehicles(markStale(vc.Vehicles, vc.SavedAt))
+		result.CacheRestored = true
+	}
+	return result, nil
+}
+
+// readBackup 读取备份包并校验格式、版本和每个文件的摘要
+func readBackup(data []byte) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("备份文件格式错误: %v", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("读取备份失败: %v", err)
+		}
+		// 备份中的文件都很小，限制大小避免异常文件占满内存
+		content, err := io.ReadAll(io.LimitReader(rc, 16<<20))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("备份文件已损坏: %v", err)
+		}
+		files[f.Name] = content
+	}
+
+	var manifest backupManifest
+	if err := json.Unmarshal(files[backupManifestFile], &manifest); err != nil || manifest.Format != backupFormat {
+		return nil, errors.New("不是有效的备份文件")
+	}
+	if manifest.Version > backupVersion {
+		return nil, fmt.Errorf("备份由更新版本的程序创建（格式版本 %d），请先升级程序", manifest.Version)
+	}
+	if manifest.ConfigVersion > configVersion {
+		return nil, fmt.Errorf("备份中的配置版本 %d 高于当前程序支持的版本 %d，请先升级程序", manifest.ConfigVersion, configVersion)
+	}
+	if _, ok := manifest.Files[backupConfigFile]; !ok {
+		return nil, errors.New("备份中没有配置文件")
+	}
+
+	verified := make(map[string][]byte, len(manifest.Files))
+	for name, want := range manifest.Files {
+		content, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("备份文件不完整，缺少 %s", name)
+		}
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != want {
+			return nil, fmt.Errorf("备份文件已损坏，%s 校验失败", name)
+		}
+		verified[name] = content
+	}
+	return verified, nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// 不带密码的备份在新电脑上导入时，没有 Token 的账号不再引用本机不存在的密钥
+func TestImportBackupWithoutTokens(t *testing.T) {
+	keyring.MockInit()
+	source := newTestApp(t, &fakeAPI{})
+	config := testConfig(5, "1", "2")
+	if err := source.saveConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+	data, err := source.exportBackup("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := newTestApp(t, &fakeAPI{})
+	local := testConfig(5, "3")
+	local.Profiles[0].Name = "p2"
+	if err := target.saveConfig(&local); err != nil {
+		t.Fatal(err)
+	}
+	result, err := target.importBackup(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TokensRestored || !reflect.DeepEqual(result.MissingTokens, []string{"p1"}) {
+		t.Fatalf("result = %+v", result)
+	}
+
+	restored := target.store.Config()
+	if p, _ := restored.profile("p1"); p.Token != "" || p.TokenRef != "" || !p.TokenMissing {
+		t.Fatalf("p1 should be marked as missing its token: %+v", p)
+	}
+	// 本机已有的账号沿用本机的 Token
+	if p, _ := restored.profile("p2"); p.Token != "3" {
+		t.Fatalf("p2 should keep the local token: %+v", p)
+	}
+}
+
+// 导入到空配置后仍然可以修改其它设置，重新填写 Token 前不刷新该账号
+func TestImportBackupIntoEmptyConfig(t *testing.T) {
+	keyring.MockInit()
+	source := newTestApp(t, &fakeAPI{})
+	config := testConfig(5, "1")
+	if err := source.saveConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+	data, err := source.exportBackup("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &fakeAPI{}
+	target := newTestApp(t, api)
+	if _, err := target.importBackup(data, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.SetActiveProfile("p1"); err != nil {
+		t.Fatalf("switch profile after import: %v", err)
+	}
+	if err := target.SetOpacity(0.8); err != nil {
+		t.Fatalf("set opacity after import: %v", err)
+	}
+
+	// 重新启动后配置文件仍然合法
+	target.loadConfig()
+	if err := target.store.Config().validate(); err != nil {
+		t.Fatalf("saved config is invalid: %v", err)
+	}
+	if profiles := target.pollableProfiles(); len(profiles) != 0 {
+		t.Fatalf("profiles without a token should not be refreshed: %+v", profiles)
+	}
+
+	// 填写 Token 后去掉标记
+	config = target.store.Config()
+	config.Profiles[0].Token = "1"
+	if err := target.saveConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+	if p := readConfigFile(t, target).Profiles[0]; p.TokenMissing || p.TokenRef == "" {
+		t.Fatalf("token should be saved and the mark cleared: %+v", p)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bestk/zeeho-widgets/internal/fsutil"
+)
+
+// 本地缓存时间格式，与接口返回的时间保持一致
+const staleTimeLayout = "2006-01-02 15:04:05"
+
+// vehicleCache 最近一次成功获取的车辆数据
+type vehicleCache struct {
+	SavedAt  time.Time     `json:"savedAt"`
+	Vehicles []VehicleData `json:"vehicles"`
+}
+
+// 缓存文件路径
+func (a *App) getCachePath() string {
+	return cachePath()
+}
+
+// cachePath 缓存文件路径，status 子命令不创建 App 直接读取
+func cachePath() string {
+	return filepath.Join(getCacheDir(), "cache.json")
+}
+
+// 加载缓存，启动时在第一次请求之前先展示上次的数据
+func (a *App) loadCache() {
+	data, err := os.ReadFile(a.getCachePath())
+	if err != nil {
+		return
+	}
+
+	var cache vehicleCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return
+	}
+
+	a.store.SetVehicles(markStale(cache.Vehicles, cache.SavedAt))
+}
+
+// 保存缓存
+func (a *App) saveCache(vehicles []VehicleData) error {
+	data, err := json.Marshal(vehicleCache{
+		SavedAt:  time.Now(),
+		Vehicles: vehicles,
+	})
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFileAtomic(a.getCachePath(), data, 0600)
+}
+
+// markStale 标记数据已过期，过期时间优先取接口返回的 RefreshTime
+func markStale(vehicles []VehicleData, fallback time.Time) []VehicleData {
+	stale := make([]VehicleData, len(vehicles))
+	for i, v := range vehicles {
+		if v.StaleSince == "" {
+			v.StaleSince = v.RefreshTime
+		}
+		if v.StaleSince == "" && !fallback.IsZero() {
+			v.StaleSince = fallback.Format(staleTimeLayout)
+		}
+		stale[i] = v
+	}
+	return stale
+}
+
+// GetCachedVehicles 获取最近一次的车辆数据，离线时带有 staleSince 标记
+func (a *App) GetCachedVehicles() []VehicleData {
+	return a.store.Vehicles()
+}
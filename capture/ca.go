@@ -0,0 +1,193 @@
+package capture
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	caCertFile = "ca.pem"
+	caKeyFile  = "ca-key.pem"
+	caName     = "Zeeho Widget Capture CA"
+)
+
+// authority 本地根证书，用于给被拦截的域名签发证书
+type authority struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+
+	mu     sync.Mutex
+	leaves map[string]*tls.Certificate
+}
+
+// loadOrCreateAuthority 从 dir 加载根证书，不存在时生成新的
+func loadOrCreateAuthority(dir string) (*authority, error) {
+	certPEM, certErr := os.ReadFile(filepath.Join(dir, caCertFile))
+	keyPEM, keyErr := os.ReadFile(filepath.Join(dir, caKeyFile))
+	if certErr == nil && keyErr == nil {
+		ca, err := parseAuthority(certPEM, keyPEM)
+		// 旧版本生成的根证书没有名称约束，可以为任意域名签发证书，需要重新生成
+		if err == nil && time.Now().Before(ca.cert.NotAfter) && constrained(ca.cert) {
+			return ca, nil
+		}
+	}
+
+	certPEM, keyPEM, err := generateAuthority()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建证书目录失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, caKeyFile), keyPEM, 0600); err != nil {
+		return nil, fmt.Errorf("保存证书私钥失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, caCertFile), certPEM, 0644); err != nil {
+		return nil, fmt.Errorf("保存证书失败: %v", err)
+	}
+
+	return parseAuthority(certPEM, keyPEM)
+}
+
+func generateAuthority() (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成证书私钥失败: %v", err)
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: caName, Organization: []string{caName}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		// 只能为 Zeeho 接口域名签发证书，私钥泄露也无法用来冒充其它网站
+		PermittedDNSDomainsCritical: true,
+		PermittedDNSDomains:         []string{Host},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成证书失败: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("导出证书私钥失败: %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// constrained 判断根证书是否只允许为 Host 签发证书
+func constrained(cert *x509.Certificate) bool {
+	return cert.PermittedDNSDomainsCritical && len(cert.PermittedDNSDomains) == 1 && cert.PermittedDNSDomains[0] == Host
+}
+
+func parseAuthority(certPEM, keyPEM []byte) (*authority, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, fmt.Errorf("证书格式错误")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析证书失败: %v", err)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("证书私钥格式错误")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析证书私钥失败: %v", err)
+	}
+
+	return &authority{
+		cert:    cert,
+		key:     key,
+		certPEM: certPEM,
+		leaves:  make(map[string]*tls.Certificate),
+	}, nil
+}
+
+// leaf 为 host 签发证书，同一个域名只签发一次
+func (ca *authority) leaf(host string) (*tls.Certificate, error) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	if cert, ok := ca.leaves[host]; ok {
+		return cert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    now.Add(-time.Hour),
+		// iOS 要求证书有效期不超过 398 天
+		NotAfter:    now.AddDate(0, 0, 365),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &tls.Certificate{
+		Certificate: [][]byte{der, ca.cert.Raw},
+		PrivateKey:  key,
+	}
+	ca.leaves[host] = cert
+	return cert, nil
+}
+
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("生成证书序列号失败: %v", err)
+	}
+	return serial, nil
+}
@@ -0,0 +1,311 @@
+package capture
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Host 需要拦截的 Zeeho 接口域名，代理只允许访问该域名
+const Host = "tapi.zeehoev.com"
+
+// Proxy 本地 HTTPS 抓包代理，从手机 App 的请求中提取 Token
+type Proxy struct {
+	ca        *authority
+	onToken   func(token string)
+	transport *http.Transport
+
+	mu        sync.Mutex
+	listener  net.Listener
+	server    *http.Server
+	lastToken string
+}
+
+// New 创建抓包代理，dir 为根证书保存目录，
+// 每捕获到一个新的 Token 都会调用 onToken
+func New(dir string, onToken func(token string)) (*Proxy, error) {
+	ca, err := loadOrCreateAuthority(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Proxy{
+		ca:      ca,
+		onToken: onToken,
+		transport: &http.Transport{
+			Proxy:               nil,
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     30 * time.Second,
+		},
+	}, nil
+}
+
+// Start 在 addr 上启动代理
+func (p *Proxy) Start(addr string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener != nil {
+		return fmt.Errorf("抓包代理已在运行")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("启动抓包代理失败: %v", err)
+	}
+
+	p.listener = listener
+	p.server = &http.Server{Handler: p}
+	go func() {
+		if err := p.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Capture proxy stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Close 停止代理
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.server == nil {
+		return nil
+	}
+	err := p.server.Close()
+	p.server = nil
+	p.listener = nil
+	p.transport.CloseIdleConnections()
+	return err
+}
+
+// Port 返回代理监听的端口，未启动时为 0
+func (p *Proxy) Port() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener == nil {
+		return 0
+	}
+	return p.listener.Addr().(*net.TCPAddr).Port
+}
+
+// CACertPEM 返回根证书，需要安装到手机上并信任
+func (p *Proxy) CACertPEM() []byte {
+	return p.ca.certPEM
+}
+
+// ServeHTTP 处理代理请求，直接访问代理地址时提供证书下载
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodConnect:
+		p.handleConnect(w, r)
+	case !r.URL.IsAbs():
+		p.serveLocal(w, r)
+	default:
+		p.handleHTTP(w, r)
+	}
+}
+
+// serveLocal 手机浏览器访问 http://电脑IP:端口/ 下载根证书
+func (p *Proxy) serveLocal(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/ca.crt", "/ca.pem":
+		w.Header().Set("Content-Type", "application/x-x509-ca-cert")
+		w.Header().Set("Content-Disposition", `attachment; filename="zeeho-widget-ca.crt"`)
+		w.Write(p.ca.certPEM)
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width">`+
+			`<title>Zeeho Widget</title></head><body><h3>Zeeho Widget 抓包证书</h3>`+
+			`<p><a href="/ca.crt">下载并安装根证书</a>，安装后在系统设置中信任该证书，然后打开 ZEEHO App 即可。</p>`+
+			`</body></html>`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleHTTP 转发明文 HTTP 请求，只允许访问 Zeeho 接口，避免局域网中的其它设备借代理访问本机服务
+func (p *Proxy) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.URL.Hostname(), Host) {
+		http.Error(w, "只允许代理 "+Host, http.StatusForbidden)
+		return
+	}
+	p.inspect(r)
+
+	r.RequestURI = ""
+	removeHopHeaders(r.Header)
+
+	resp, err := p.transport.RoundTrip(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	removeHopHeaders(resp.Header)
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// handleConnect 处理 HTTPS 隧道，只允许连接 Zeeho 接口并解密其中的流量。
+// 其它目标一律拒绝，避免局域网中的其它设备把代理当作跳板访问本机或内网的服务
+func (p *Proxy) handleConnect(w http.ResponseWriter, r *http.Request) {
+	host, port, err := net.SplitHostPort(r.Host)
+	if err != nil || port != "443" || !strings.EqualFold(strings.TrimSuffix(host, "."), Host) {
+		http.Error(w, "只允许代理 "+Host+":443", http.StatusForbidden)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		return
+	}
+	p.intercept(conn, Host)
+}
+
+// intercept 用本地根证书签发的证书与手机建立 TLS，读取请求后转发到真实服务器
+func (p *Proxy) intercept(conn net.Conn, host string) {
+	tlsConn := tls.Server(conn, &tls.Config{
+		NextProtos: []string{"http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return p.ca.leaf(host)
+		},
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		log.Printf("Capture TLS handshake failed (is the CA certificate trusted?): %v", err)
+		return
+	}
+	defer tlsConn.Close()
+
+	reader := bufio.NewReader(tlsConn)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+
+		// 始终转发到隧道的目标域名，不使用请求中的 Host
+		req.URL.Scheme = "https"
+		req.URL.Host = host
+		req.RequestURI = ""
+		p.inspect(req)
+		removeHopHeaders(req.Header)
+
+		resp, err := p.transport.RoundTrip(req)
+		if err != nil {
+			resp = &http.Response{
+				StatusCode: http.StatusBadGateway,
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(err.Error())),
+			}
+		}
+
+		err = resp.Write(tlsConn)
+		resp.Body.Close()
+		if err != nil || req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+// inspect 从发往 Zeeho 接口的请求中提取 Token
+func (p *Proxy) inspect(r *http.Request) {
+	if !strings.EqualFold(r.URL.Hostname(), Host) {
+		return
+	}
+
+	token := BearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		return
+	}
+
+	p.mu.Lock()
+	isNew := token != p.lastToken
+	p.lastToken = token
+	p.mu.Unlock()
+
+	if isNew && p.onToken != nil {
+		go p.onToken(token)
+	}
+}
+
+// BearerToken 从 Authorization 头中取出 Token，不是 Bearer 格式时返回空
+func BearerToken(authorization string) string {
+	const prefix = "bearer "
+	authorization = strings.TrimSpace(authorization)
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(authorization[len(prefix):])
+}
+
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+}
+
+// LocalAddresses 返回本机的局域网 IPv4 地址，用于在手机上配置代理
+func LocalAddresses() []string {
+	var addrs []string
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return addrs
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range ifaceAddrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil {
+				continue
+			}
+			addrs = append(addrs, ipNet.IP.String())
+		}
+	}
+	return addrs
+}
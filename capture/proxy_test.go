@@ -0,0 +1,65 @@
+package capture
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// 代理只允许访问 Zeeho 接口
+func TestProxyRefusesOtherHosts(t *testing.T) {
+	p, err := New(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{"example.com:443", "127.0.0.1:22", "localhost:443", "192.168.1.1:443", Host + ":80"} {
+		r := httptest.NewRequest(http.MethodConnect, "http://"+target, nil)
+		r.Host = target
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("CONNECT %s: status %d, want 403", target, w.Code)
+		}
+	}
+
+	for _, url := range []string{"http://127.0.0.1:8080/", "http://example.com/"} {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("GET %s: status %d, want 403", url, w.Code)
+		}
+	}
+}
+
+// 根证书只能为 Zeeho 接口签发证书
+func TestAuthorityIsConstrained(t *testing.T) {
+	p, err := New(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(p.CACertPEM())
+	root, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	for host, ok := range map[string]bool{Host: true, "example.com": false} {
+		leaf, err := p.ca.leaf(host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(leaf.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: host})
+		if (err == nil) != ok {
+			t.Errorf("verify %s: %v", host, err)
+		}
+	}
+}
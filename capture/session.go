@@ -0,0 +1,150 @@
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// 车辆小组件接口路径中的车辆ID
+var vehicleIDPattern = regexp.MustCompile(`/vehicle/widgets/([^/?#]+)`)
+
+// Session 从抓包文件中提取的配置信息
+type Session struct {
+	Token      string   `json:"token"`
+	Cookie     string   `json:"cookie"`
+	VehicleIDs []string `json:"vehicleIds"`
+}
+
+// harFile HAR 格式（浏览器、Fiddler、HttpCanary 等导出）
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL     string      `json:"url"`
+				Headers []harHeader `json:"headers"`
+				Cookies []harHeader `json:"cookies"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// charlesTransaction Charles 导出的 JSON Session（.chlsj）
+type charlesTransaction struct {
+	Host    string `json:"host"`
+	Path    string `json:"path"`
+	Request struct {
+		Header struct {
+			Headers []harHeader `json:"headers"`
+		} `json:"header"`
+	} `json:"request"`
+}
+
+// ParseSession 解析 HAR 或 Charles .chlsj 文件，提取发往 Zeeho 接口的
+// Token、acw_tc Cookie 和车辆ID，多次出现时以最后一次请求为准
+func ParseSession(data []byte) (*Session, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return nil, fmt.Errorf("抓包文件为空")
+	}
+
+	session := &Session{}
+	var err error
+	if trimmed[0] == '[' {
+		err = parseCharles(data, session)
+	} else {
+		err = parseHAR(data, session)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Token == "" && len(session.VehicleIDs) == 0 {
+		return nil, fmt.Errorf("未找到 %s 的请求", Host)
+	}
+	return session, nil
+}
+
+func parseHAR(data []byte, session *Session) error {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return fmt.Errorf("解析HAR文件失败: %v", err)
+	}
+
+	for _, entry := range har.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || !strings.EqualFold(u.Hostname(), Host) {
+			continue
+		}
+
+		session.add(u.Path, toHeader(entry.Request.Headers))
+		for _, c := range entry.Request.Cookies {
+			if c.Name == "acw_tc" && c.Value != "" {
+				session.Cookie = c.Value
+			}
+		}
+	}
+	return nil
+}
+
+func parseCharles(data []byte, session *Session) error {
+	var transactions []charlesTransaction
+	if err := json.Unmarshal(data, &transactions); err != nil {
+		return fmt.Errorf("解析Charles文件失败: %v", err)
+	}
+
+	for _, t := range transactions {
+		if !strings.EqualFold(t.Host, Host) {
+			continue
+		}
+		session.add(t.Path, toHeader(t.Request.Header.Headers))
+	}
+	return nil
+}
+
+// add 从一次请求中提取信息
+func (s *Session) add(path string, header http.Header) {
+	if token := BearerToken(header.Get("Authorization")); token != "" {
+		s.Token = token
+	}
+
+	if cookie := AcwTC(header); cookie != "" {
+		s.Cookie = cookie
+	}
+
+	if m := vehicleIDPattern.FindStringSubmatch(path); m != nil {
+		for _, id := range s.VehicleIDs {
+			if id == m[1] {
+				return
+			}
+		}
+		s.VehicleIDs = append(s.VehicleIDs, m[1])
+	}
+}
+
+// AcwTC 从请求头中取出 acw_tc Cookie
+func AcwTC(header http.Header) string {
+	req := http.Request{Header: header}
+	cookie, err := req.Cookie("acw_tc")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func toHeader(headers []harHeader) http.Header {
+	header := make(http.Header, len(headers))
+	for _, h := range headers {
+		// HTTP/2 抓包中的头部名称为小写，统一规范化
+		header.Add(h.Name, h.Value)
+	}
+	return header
+}
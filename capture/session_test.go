@@ -0,0 +1,97 @@
+package capture
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSession(t *testing.T) {
+	tests := []struct {
+		file    string
+		want    *Session
+		wantErr bool
+	}{
+		{
+			// 多次出现时以最后一次为准，其它域名的请求被忽略，车辆ID去重
+			file: "session.har",
+			want: &Session{Token: "new-token", Cookie: "new-cookie", VehicleIDs: []string{"VIN001", "VIN002"}},
+		},
+		{
+			file: "session.chlsj",
+			want: &Session{Token: "charles-token", Cookie: "charles-cookie", VehicleIDs: []string{"VIN003"}},
+		},
+		{
+			// 只有非 Bearer 的 Authorization，也没有车辆ID
+			file:    "no-token.har",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseSession(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSession error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSession = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSessionMalformed(t *testing.T) {
+	for name, data := range map[string]string{
+		"empty":          "  \n",
+		"not json":       "HTTP/1.1 200 OK",
+		"truncated har":  `{"log": {"entries": [`,
+		"truncated chls": `[{"host": "tapi.zeehoev.com"`,
+		"wrong type":     `{"log": {"entries": {}}}`,
+		"no requests":    `{"log": {"entries": []}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if s, err := ParseSession([]byte(data)); err == nil {
+				t.Errorf("ParseSession = %+v, want an error", s)
+			}
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	for header, want := range map[string]string{
+		"Bearer abc":     "abc",
+		"bearer  abc  ":  "abc",
+		"  BEARER abc":   "abc",
+		"Bearer ":        "",
+		"Basic dXNlcg==": "",
+		"abc":            "",
+		"":               "",
+	} {
+		if got := BearerToken(header); got != want {
+			t.Errorf("BearerToken(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestAcwTC(t *testing.T) {
+	for cookie, want := range map[string]string{
+		"acw_tc=abc":               "abc",
+		"foo=bar; acw_tc=abc; x=y": "abc",
+		"foo=bar":                  "",
+		"":                         "",
+	} {
+		header := http.Header{}
+		if cookie != "" {
+			header.Set("Cookie", cookie)
+		}
+		if got := AcwTC(header); got != want {
+			t.Errorf("AcwTC(%q) = %q, want %q", cookie, got, want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bestk/zeeho-widgets/backend"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// 默认的临时关闭鼠标穿透的快捷键
+	defaultHotkey = "Ctrl+Alt+Z"
+	// 最低不透明度，避免窗口完全看不见
+	minOpacity = 0.2
+)
+
+// opacity 返回窗口不透明度，未设置时为 1
+func (w WindowConfig) opacity() float64 {
+	if w.Opacity == 0 {
+		return 1
+	}
+	return w.Opacity
+}
+
+// hotkey 返回临时关闭鼠标穿透的快捷键，未设置时使用默认值
+func (w WindowConfig) hotkey() string {
+	if w.Hotkey == "" {
+		return defaultHotkey
+	}
+	return w.Hotkey
+}
+
+// desktopSettings 已经应用到窗口的透明度和鼠标穿透
+type desktopSettings struct {
+	opacity      float64
+	clickThrough bool
+	hotkey       string // 已注册的快捷键，为空表示没有注册
+}
+
+// applyDesktopSettings 将配置中的透明度和鼠标穿透应用到窗口，并按需注册快捷键。
+// force 为 false 时只应用变化的设置；进入小部件模式等会重置窗口属性的操作之后需要 force
+func (a *App) applyDesktopSettings(force bool) {
+	window := a.effectiveConfig().Window
+	caps := a.desktop.Capabilities()
+
+	a.desktopMu.Lock()
+	defer a.desktopMu.Unlock()
+
+	if !window.ClickThrough {
+		a.clickPaused = false
+	}
+	applied := a.desktopApplied
+	want := desktopSettings{
+		opacity:      window.opacity(),
+		clickThrough: window.ClickThrough && !a.clickPaused,
+		hotkey:       applied.hotkey,
+	}
+
+	if caps.Opacity && (force || want.opacity != applied.opacity) {
+		if err := a.desktop.SetOpacity(want.opacity); err != nil {
+			a.reportDesktopError("设置透明度", err)
+		}
+	}
+	if caps.ClickThrough && (force || want.clickThrough != applied.clickThrough) {
+		if err := a.desktop.SetClickThrough(want.clickThrough); err != nil {
+			a.reportDesktopError("设置鼠标穿透", err)
+		}
+	}
+
+	// 只在开启鼠标穿透时注册快捷键，避免占用其它程序的快捷键
+	hotkey := ""
+	if caps.Hotkey && window.ClickThrough {
+		hotkey = window.hotkey()
+	}
+	if hotkey != applied.hotkey {
+		if a.stopHotkey != nil {
+			a.stopHotkey()
+			a.stopHotkey = nil
+		}
+		want.hotkey = ""
+		if hotkey != "" {
+			if err := a.watchHotkey(hotkey); err != nil {
+				a.reportDesktopError("注册快捷键", err)
+			} else {
+				want.hotkey = hotkey
+			}
+		}
+	}
+
+	a.desktopApplied = want
+}
+
+// watchHotkey 注册临时关闭鼠标穿透的快捷键，调用方需要持有 desktopMu
+func (a *App) watchHotkey(name string) error {
+	hotkey, err := backend.ParseHotkey(name)
+	if err != nil {
+		return err
+	}
+	stop, err := a.desktop.WatchHotkey(hotkey, func() {
+		a.desktopMu.Lock()
+		paused := !a.clickPaused
+		a.desktopMu.Unlock()
+		a.SetClickThroughPaused(paused)
+	})
+	if err != nil {
+		return err
+	}
+	a.stopHotkey = stop
+	return nil
+}
+
+// stopDesktopSettings 退出时取消注册快捷键
+func (a *App) stopDesktopSettings() {
+	a.desktopMu.Lock()
+	defer a.desktopMu.Unlock()
+	if a.stopHotkey != nil {
+		a.stopHotkey()
+		a.stopHotkey = nil
+	}
+}
+
+// SetOpacity 设置并保存窗口不透明度，0.2-1
+func (a *App) SetOpacity(opacity float64) error {
+	config := a.store.Config()
+	config.Window.Opacity = opacity
+	if err := config.validate(); err != nil {
+		return err
+	}
+	if err := a.saveConfig(&config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	return nil
+}
+
+// SetClickThrough 设置并保存鼠标穿透。开启后可以通过快捷键临时关闭
+func (a *App) SetClickThrough(enabled bool) error {
+	if enabled && !a.desktop.Capabilities().ClickThrough {
+		return desktopError("鼠标穿透", backend.ErrUnsupported)
+	}
+	config := a.store.Config()
+	config.Window.ClickThrough = enabled
+	if err := a.saveConfig(&config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	return nil
+}
+
+// SetClickThroughPaused 临时关闭或恢复鼠标穿透，不修改配置，重新启动后恢复
+func (a *App) SetClickThroughPaused(paused bool) {
+	a.desktopMu.Lock()
+	if !a.effectiveConfig().Window.ClickThrough {
+		paused = false
+	}
+	changed := a.clickPaused != paused
+	a.clickPaused = paused
+	a.desktopMu.Unlock()
+
+	if !changed {
+		return
+	}
+	a.applyDesktopSettings(false)
+	a.updateTray()
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "clickThroughPaused", paused)
+	}
+}
+
+// IsClickThroughPaused 是否临时关闭了鼠标穿透
+func (a *App) IsClickThroughPaused() bool {
+	a.desktopMu.Lock()
+	defer a.desktopMu.Unlock()
+	return a.clickPaused
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bestk/zeeho-widgets/backend"
+	"github.com/bestk/zeeho-widgets/internal/fsutil"
+)
+
+// 配置文件格式的当前版本，每次不兼容的格式变化都需要加一并在 configMigrations 中添加迁移
+const configVersion = 1
+
+// 配置、密钥、缓存所在的目录名
+const appDirName = "zeeho-widgets"
+
+// configMigration 将配置从上一个版本升级到下一个版本，
+// 直接修改解析后的 JSON 字段，避免依赖旧版本的结构体定义
+type configMigration func(raw map[string]json.RawMessage) error
+
+// configMigrations 第 i 项将版本 i 的配置升级到版本 i+1
+var configMigrations = []configMigration{
+	migrateLegacyProfile,
+}
+
+// migrateLegacyProfile 版本 0：单账号的平铺配置迁移为默认账号。
+// 没有版本号但已经是多账号格式的配置保持不变
+func migrateLegacyProfile(raw map[string]json.RawMessage) error {
+	if _, ok := raw["profiles"]; ok {
+		return nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	var legacy legacyConfig
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	profiles := []Profile{}
+	if profile, ok := legacy.profile(); ok {
+		profiles = append(profiles, profile)
+	}
+	if raw["profiles"], err = json.Marshal(profiles); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"token", "tokenRef", "vehicleId", "cookie", "selectedVehicles"} {
+		delete(raw, key)
+	}
+	return nil
+}
+
+// parseConfig 解析配置文件并升级到当前版本，返回升级前的版本号
+func parseConfig(data []byte) (Config, int, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Config{}, 0, fmt.Errorf("配置文件格式错误: %v", err)
+	}
+	if raw == nil {
+		raw = make(map[string]json.RawMessage)
+	}
+
+	version := 0
+	if v, ok := raw["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return Config{}, 0, fmt.Errorf("配置文件版本号无效: %s", v)
+		}
+	}
+	if version < 0 || version > configVersion {
+		return Config{}, version, fmt.Errorf("配置文件版本 %d 不受支持，当前程序支持的最高版本为 %d", version, configVersion)
+	}
+
+	for v := version; v < configVersion; v++ {
+		if err := configMigrations[v](raw); err != nil {
+			return Config{}, version, fmt.Errorf("配置从版本 %d 升级失败: %v", v, err)
+		}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return Config{}, version, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, version, fmt.Errorf("配置文件格式错误: %v", err)
+	}
+	config.Version = configVersion
+	return config, version, nil
+}
+
+// withoutTokens 去掉旧版本配置中的明文 Token，用于升级前的备份
+func withoutTokens(data []byte) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	delete(raw, "token")
+
+	if profiles, ok := raw["profiles"]; ok {
+		var list []map[string]json.RawMessage
+		if err := json.Unmarshal(profiles, &list); err != nil {
+			return nil, err
+		}
+		for _, p := range list {
+			delete(p, "token")
+		}
+		var err error
+		if raw["profiles"], err = json.Marshal(list); err != nil {
+			return nil, err
+		}
+	}
+	return json.MarshalIndent(raw, "", "  ")
+}
+
+// FieldError 单个配置字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors 配置校验错误，包含所有不合法的字段
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return "配置无效: " + strings.Join(msgs, "; ")
+}
+
+// validate 校验配置字段，返回全部不合法的字段而不是只返回第一个
+func (c Config) validate() error {
+	var errs ValidationErrors
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(c.Profiles) > 0 && (c.UpdateInterval < 1 || c.UpdateInterval > 60) {
+		add("updateInterval", "更新间隔必须在1-60分钟之间")
+	}
+
+	names := make(map[string]bool, len(c.Profiles))
+	for i, p := range c.Profiles {
+		field := fmt.Sprintf("profiles[%d]", i)
+		switch {
+		case strings.TrimSpace(p.Name) == "":
+			add(field+".name", "账号名称不能为空")
+		case len(p.Name) > 32:
+			add(field+".name", "账号名称不能超过32个字符")
+		case names[p.Name]:
+			add(field+".name", "账号名称重复: %s", p.Name)
+		}
+		names[p.Name] = true
+
+		if p.Token == "" && p.TokenRef == "" && !p.TokenMissing {
+			add(field+".token", "Token不能为空")
+		}
+		if strings.ContainsAny(p.Token, " \t\r\n") {
+			add(field+".token", "Token不能包含空白字符")
+		}
+		if strings.HasPrefix(p.Token, "Bearer ") {
+			add(field+".token", "Token不需要包含 Bearer 前缀")
+		}
+		if strings.ContainsAny(p.VehicleID, "/?# ") {
+			add(field+".vehicleId", "车架号格式无效")
+		}
+		for j, vin := range p.SelectedVehicles {
+			if strings.TrimSpace(vin) == "" {
+				add(fmt.Sprintf("%s.selectedVehicles[%d]", field, j), "车架号不能为空")
+			}
+		}
+	}
+
+	if c.ActiveProfile != "" && !names[c.ActiveProfile] {
+		add("activeProfile", "账号不存在: %s", c.ActiveProfile)
+	}
+
+	if m := c.Window.Margins; m != nil {
+		for _, side := range []struct {
+			name  string
+			value int
+		}{{"top", m.Top}, {"right", m.Right}, {"bottom", m.Bottom}, {"left", m.Left}} {
+			if side.value < 0 || side.value > 1000 {
+				add("window.margins."+side.name, "边距必须在0-1000之间")
+			}
+		}
+	}
+	if a := c.Window.Anchor; a != nil {
+		if a.X < 0 || a.X > 1 {
+			add("window.anchor.x", "位置必须在0-1之间")
+		}
+		if a.Y < 0 || a.Y > 1 {
+			add("window.anchor.y", "位置必须在0-1之间")
+		}
+	}
+	switch c.Window.Layer {
+	case "", "background", "bottom":
+	default:
+		add("window.layer", "层必须是 background 或 bottom")
+	}
+	if o := c.Window.Opacity; o != 0 && (o < minOpacity || o > 1) {
+		add("window.opacity", "不透明度必须在%v-1之间", minOpacity)
+	}
+	if c.Window.Hotkey != "" {
+		if _, err := backend.ParseHotkey(c.Window.Hotkey); err != nil {
+			add("window.hotkey", "%v", err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// 配置目录，遵循 XDG 规范（$XDG_CONFIG_HOME/zeeho-widgets），
+// Windows 和 macOS 上为对应的应用数据目录
+func getConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		dir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(dir, appDirName)
+}
+
+// 缓存目录（$XDG_CACHE_HOME/zeeho-widgets）
+func getCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		dir = filepath.Join(homeDir, ".cache")
+	}
+	return filepath.Join(dir, appDirName)
+}
+
+// migrateLegacyPaths 将旧版本保存在用户主目录下的文件移动到新的目录。
+// 新位置已存在文件时保留旧文件不做处理
+func (a *App) migrateLegacyPaths() {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+
+	moves := []struct{ from, to string }{
+		{filepath.Join(homeDir, ".zeeho-config.json"), filepath.Join(getConfigDir(), "config.json")},
+		{filepath.Join(homeDir, ".zeeho-secrets.json"), a.getSecretsPath()},
+		{filepath.Join(homeDir, ".zeeho-cache.json"), a.getCachePath()},
+		{filepath.Join(homeDir, ".zeeho-capture"), a.getCaptureDir()},
+	}
+	for _, m := range moves {
+		if err := moveLegacyPath(m.from, m.to); err != nil {
+			log.Printf("Move %s to %s failed: %v", m.from, m.to, err)
+		}
+	}
+}
+
+func moveLegacyPath(from, to string) error {
+	info, err := os.Stat(from)
+	if err != nil {
+		return nil
+	}
+	if _, err := os.Stat(to); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0700); err != nil {
+		return err
+	}
+
+	if err := os.Rename(from, to); err == nil || info.IsDir() {
+		return err
+	}
+
+	// 跨文件系统时无法直接重命名，复制后删除旧文件
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	if err := fsutil.WriteFileAtomic(to, data, 0600); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
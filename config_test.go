@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bestk/zeeho-widgets/backend"
+	"github.com/bestk/zeeho-widgets/internal/fsutil"
+	"github.com/zalando/go-keyring"
+)
+
+// 无法解析的配置文件（例如由更新的版本写入）不会被覆盖
+func TestUnreadableConfigIsNotOverwritten(t *testing.T) {
+	keyring.MockInit()
+	app := newTestApp(t, &fakeAPI{})
+	data := []byte(`{"version": 99, "profiles": [{"name": "p1", "tokenRef": "keyring"}]}`)
+	if err := fsutil.WriteFileAtomic(app.getConfigPath(), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app.loadConfig()
+	// 关闭窗口时保存窗口状态
+	app.setWindowState(WindowState{Bounds: backend.Rect{Width: 300, Height: 200}})
+	config := testConfig(1, "1")
+	if err := app.saveConfig(&config); err == nil {
+		t.Fatal("saving over an unreadable config should fail")
+	}
+	if got, _ := os.ReadFile(app.getConfigPath()); !bytes.Equal(got, data) {
+		t.Fatalf("config overwritten: %s", got)
+	}
+
+	// 删除文件后可以重新保存
+	os.Remove(app.getConfigPath())
+	app.reloadConfig()
+	if err := app.saveConfig(&config); err != nil {
+		t.Fatalf("save after removing unreadable config: %v", err)
+	}
+}
+
+// 运行中配置文件被更新的版本改写后，旧版本不再保存，修正后恢复
+func TestReloadUnsupportedVersionBlocksSaves(t *testing.T) {
+	keyring.MockInit()
+	app := newTestApp(t, &fakeAPI{})
+	config := testConfig(1, "1")
+	if err := app.saveConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(`{"version": 99, "profiles": [{"name": "p1", "tokenRef": "keyring"}]}`)
+	if err := fsutil.WriteFileAtomic(app.getConfigPath(), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	app.reloadConfig()
+	if err := app.SetActiveProfile("p1"); err == nil {
+		t.Fatal("saving over a config from a newer version should fail")
+	}
+	if got, _ := os.ReadFile(app.getConfigPath()); !bytes.Equal(got, data) {
+		t.Fatalf("config overwritten: %s", got)
+	}
+
+	data = []byte(`{"version": 1, "profiles": [{"name": "p1", "tokenRef": "keyring"}], "updateInterval": 1}`)
+	if err := fsutil.WriteFileAtomic(app.getConfigPath(), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	app.reloadConfig()
+	if err := app.SetActiveProfile("p1"); err != nil {
+		t.Fatalf("save after fixing the config: %v", err)
+	}
+}
+
+// 升级前的备份中不包含明文 Token
+func TestMigrationBackupHasNoToken(t *testing.T) {
+	keyring.MockInit()
+	app := newTestApp(t, &fakeAPI{})
+	data := []byte(`{"token": "secret-token", "vehicleId": "VIN1", "updateInterval": 5}`)
+	if err := fsutil.WriteFileAtomic(app.getConfigPath(), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app.loadConfig()
+	backup, err := os.ReadFile(app.getConfigPath() + ".v0.bak")
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if strings.Contains(string(backup), "secret-token") || !strings.Contains(string(backup), "VIN1") {
+		t.Fatalf("backup should keep settings without the token: %s", backup)
+	}
+	if config := app.store.Config(); len(config.Profiles) != 1 || config.Profiles[0].Token != "secret-token" {
+		t.Fatalf("token not migrated: %+v", config.Profiles)
+	}
+}
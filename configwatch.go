@@ -0,0 +1,130 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 编辑器保存文件时通常会产生多个事件，合并后再重新加载
+const configReloadDebounce = 500 * time.Millisecond
+
+// watchConfig 监听配置文件的外部修改（手动编辑、部署脚本），修改后重新加载。
+// 监听的是配置目录而不是文件本身，编辑器和原子写入都会替换文件
+func (a *App) watchConfig() error {
+	configPath := a.getConfigPath()
+	dir := filepath.Dir(configPath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	a.configMu.Lock()
+	a.watcher = watcher
+	a.configMu.Unlock()
+
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != configPath || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(configReloadDebounce, a.reloadConfig)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Watch config failed: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// stopWatchConfig 停止监听配置文件
+func (a *App) stopWatchConfig() {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+
+	if a.watcher != nil {
+		a.watcher.Close()
+		a.watcher = nil
+	}
+}
+
+// reloadConfig 重新读取并校验配置文件，合法时替换当前配置，
+// 状态中心的订阅者会通知前端并重新安排定时任务。不合法时保留当前配置；
+// 无法解析（包括由更新的版本写入）时与启动时一样禁止保存，避免覆盖该文件
+func (a *App) reloadConfig() {
+	data, err := os.ReadFile(a.getConfigPath())
+	if os.IsNotExist(err) {
+		// 无法读取的配置文件被删除后可以重新保存
+		a.configMu.Lock()
+		a.configErr = nil
+		a.configMu.Unlock()
+	}
+	if err != nil {
+		// 文件被删除或正在被替换，保留当前配置
+		return
+	}
+
+	// 忽略程序自己写入的内容
+	a.configMu.Lock()
+	own := sha256.Sum256(data) == a.configHash
+	a.configMu.Unlock()
+	if own {
+		return
+	}
+
+	config, version, err := parseConfig(data)
+	if err != nil {
+		a.configMu.Lock()
+		a.configErr = err
+		a.configMu.Unlock()
+	} else {
+		err = config.validate()
+	}
+	if err != nil {
+		log.Printf("Reload config failed, keeping current config: %v", err)
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "configError", fmt.Sprintf("配置文件修改未生效: %v", err))
+		}
+		return
+	}
+
+	log.Println("Config file changed, reloading")
+	a.configMu.Lock()
+	a.configErr = nil
+	a.configMu.Unlock()
+	a.loadTokens(&config, version < configVersion)
+	a.store.SetConfig(config)
+}
+
+// rememberConfig 记录程序写入的配置内容，避免监听到自己的修改后重复加载
+func (a *App) rememberConfig(data []byte) {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+	a.configHash = sha256.Sum256(data)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/bestk/zeeho-widgets/backend"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// errRestartToExitWidget 当前平台无法直接退出小部件模式
+var errRestartToExitWidget = errors.New("当前平台无法直接退出小部件模式，重启程序后恢复为普通窗口")
+
+// desktopError 生成返回给前端的错误信息
+func desktopError(action string, err error) error {
+	if errors.Is(err, backend.ErrUnsupported) {
+		return fmt.Errorf("当前平台不支持%s", action)
+	}
+	return fmt.Errorf("%s失败: %v", action, err)
+}
+
+// reportDesktopError 记录没有调用方可以接收的桌面集成错误，并通知前端
+func (a *App) reportDesktopError(action string, err error) {
+	err = desktopError(action, err)
+	log.Println(err)
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "desktopError", err.Error())
+	}
+}
+
+// prepareDesktop 窗口创建后完成透明背景等初始设置
+func (a *App) prepareDesktop() {
+	if err := a.desktop.Prepare(); err != nil {
+		a.reportDesktopError("设置透明背景", err)
+	}
+	a.applyDesktopSettings(true)
+}
+
+// GetDesktopCapabilities 获取当前平台支持的桌面集成功能
+func (a *App) GetDesktopCapabilities() backend.Capabilities {
+	return a.desktop.Capabilities()
+}
+
+// GetDesktopState 获取窗口当前的小部件模式、透明度、鼠标穿透等状态
+func (a *App) GetDesktopState() (backend.DesktopState, error) {
+	state, err := a.desktop.State()
+	if err != nil {
+		return state, desktopError("获取窗口状态", err)
+	}
+	return state, nil
+}
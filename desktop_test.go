@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bestk/zeeho-widgets/backend"
+	"github.com/zalando/go-keyring"
+)
+
+// 主显示器 1920×1080，右侧为缩放比例 2 的 4K 显示器
+var testMonitors = []backend.Monitor{
+	{
+		ID:       "primary",
+		Primary:  true,
+		Bounds:   backend.Rect{Width: 1920, Height: 1080},
+		WorkArea: backend.Rect{Width: 1920, Height: 1040},
+		Scale:    1,
+	},
+	{
+		ID:       "right",
+		Bounds:   backend.Rect{X: 1920, Width: 3840, Height: 2160},
+		WorkArea: backend.Rect{X: 1920, Width: 3840, Height: 2160},
+		Scale:    2,
+	},
+}
+
+// newDesktopTestApp 创建使用 Fake 桌面集成的 App，窗口位于主显示器
+func newDesktopTestApp(t *testing.T) (*App, *backend.Fake) {
+	t.Helper()
+	keyring.MockInit()
+	app := newTestApp(t, &fakeAPI{})
+	fake := backend.NewFake(testMonitors, backend.Rect{X: 100, Y: 100, Width: 300, Height: 200})
+	app.desktop = fake
+	return app, fake
+}
+
+func TestToggleWidget(t *testing.T) {
+	app, fake := newDesktopTestApp(t)
+	config := testConfig(5, "1")
+	config.Window.Anchor = &Anchor{X: 0, Y: 1}
+	app.store.SetConfig(config)
+
+	widget, err := app.ToggleWidget()
+	if err != nil || !widget {
+		t.Fatalf("enter widget mode: %v %v", widget, err)
+	}
+	if state, _ := fake.State(); !state.Widget {
+		t.Fatal("window is not in widget mode")
+	}
+	if fake.Options.AnchorX != 0 || fake.Options.AnchorY != 1 {
+		t.Errorf("widget options = %+v", fake.Options)
+	}
+	if state := app.store.Config().Window.State; state == nil || !state.Widget {
+		t.Errorf("widget mode not saved: %+v", state)
+	}
+
+	widget, err = app.ToggleWidget()
+	if err != nil || widget {
+		t.Fatalf("exit widget mode: %v %v", widget, err)
+	}
+	if state, _ := fake.State(); state.Widget {
+		t.Fatal("window is still in widget mode")
+	}
+	if state := app.store.Config().Window.State; state == nil || state.Widget {
+		t.Errorf("widget mode not saved: %+v", state)
+	}
+}
+
+// 无法退出小部件模式的平台（Wayland）取消保存的小部件模式，重启后恢复为普通窗口
+func TestStopWidgetWithoutExit(t *testing.T) {
+	app, fake := newDesktopTestApp(t)
+	fake.Caps.ExitWidgetMode = false
+	app.store.SetConfig(testConfig(5, "1"))
+
+	if widget, err := app.ToggleWidget(); err != nil || !widget {
+		t.Fatalf("enter widget mode: %v %v", widget, err)
+	}
+	widget, err := app.ToggleWidget()
+	if !errors.Is(err, errRestartToExitWidget) || !widget {
+		t.Fatalf("ToggleWidget = %v, %v, want errRestartToExitWidget", widget, err)
+	}
+	if state, _ := fake.State(); !state.Widget {
+		t.Fatal("window should stay in widget mode until restart")
+	}
+	if state := app.store.Config().Window.State; state == nil || state.Widget {
+		t.Fatalf("widget mode should not be restored on the next start: %+v", state)
+	}
+}
+
+func TestUnsupportedDesktopFeatures(t *testing.T) {
+	app, fake := newDesktopTestApp(t)
+	fake.Caps.WidgetMode = false
+
+	fake.Caps.ClickThrough = false
+	if err := app.SetClickThrough(true); err == nil {
+		t.Error("click-through should be refused when unsupported")
+	}
+	if widget, err := app.ToggleWidget(); err == nil || widget {
+		t.Fatalf("ToggleWidget = %v, %v, want an error", widget, err)
+	}
+	if state := app.store.Config().Window.State; state != nil {
+		t.Errorf("window state should not change: %+v", state)
+	}
+}
+
+// 透明度和鼠标穿透随配置应用到窗口，快捷键临时关闭鼠标穿透
+func TestApplyDesktopSettings(t *testing.T) {
+	app, fake := newDesktopTestApp(t)
+	hotkey, err := backend.ParseHotkey(defaultHotkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := testConfig(5, "1")
+	config.Window.Opacity = 0.5
+	config.Window.ClickThrough = true
+	app.store.SetConfig(config)
+
+	state, _ := fake.State()
+	if state.Opacity != 0.5 || !state.ClickThrough {
+		t.Fatalf("settings not applied: %+v", state)
+	}
+
+	if !fake.Press(hotkey) {
+		t.Fatal("hotkey not registered")
+	}
+	if state, _ := fake.State(); state.ClickThrough || !app.IsClickThroughPaused() {
+		t.Fatalf("hotkey should pause click-through: %+v", state)
+	}
+	fake.Press(hotkey)
+	if state, _ := fake.State(); !state.ClickThrough {
+		t.Fatalf("hotkey should resume click-through: %+v", state)
+	}
+
+	// 关闭鼠标穿透后取消注册快捷键
+	config.Window.ClickThrough = false
+	app.store.SetConfig(config)
+	if state, _ := fake.State(); state.ClickThrough {
+		t.Fatalf("click-through not disabled: %+v", state)
+	}
+	if fake.Press(hotkey) {
+		t.Fatal("hotkey still registered")
+	}
+}
+
+// 移动到配置的显示器，边距按该显示器的缩放比例换算
+func TestMoveToCorner(t *testing.T) {
+	app, fake := newDesktopTestApp(t)
+	config := testConfig(5, "1")
+	config.Window.Monitor = "right"
+	config.Window.Margins = &Margins{10, 10, 10, 10}
+	app.store.SetConfig(config)
+
+	if err := app.MoveToCorner("top-right"); err != nil {
+		t.Fatal(err)
+	}
+	bounds, _ := fake.WindowBounds()
+	if bounds.X != 1920+3840-20-300 || bounds.Y != 20 {
+		t.Fatalf("window at %d,%d", bounds.X, bounds.Y)
+	}
+
+	if err := app.MoveToCorner("nowhere"); err == nil {
+		t.Fatal("unknown position should be rejected")
+	}
+}
+
+// 保存在副显示器上的窗口恢复到原来的位置，显示器断开后移动到当前显示器
+func TestRestoreWindowState(t *testing.T) {
+	app, fake := newDesktopTestApp(t)
+	config := testConfig(5, "1")
+	saved := backend.Rect{X: 3000, Y: 500, Width: 300, Height: 200}
+	config.Window.State = &WindowState{Bounds: saved, Monitor: "right"}
+	app.store.SetConfig(config)
+
+	app.restoreWindowState()
+	if bounds, _ := fake.WindowBounds(); bounds != saved {
+		t.Fatalf("window restored to %+v, want %+v", bounds, saved)
+	}
+
+	fake.SetMonitors(testMonitors[:1])
+	app.restoreWindowState()
+	if bounds, _ := fake.WindowBounds(); bounds != (backend.Rect{X: 1620, Y: 500, Width: 300, Height: 200}) {
+		t.Fatalf("window restored to %+v after the monitor was removed", bounds)
+	}
+}
+
+func TestFitToMonitors(t *testing.T) {
+	tests := []struct {
+		name    string
+		bounds  backend.Rect
+		monitor string
+		want    backend.Rect
+	}{
+		{
+			name:    "still attached",
+			bounds:  backend.Rect{X: 3000, Y: 500, Width: 300, Height: 200},
+			monitor: "right",
+			want:    backend.Rect{X: 3000, Y: 500, Width: 300, Height: 200},
+		},
+		{
+			name:   "no monitor recorded",
+			bounds: backend.Rect{X: 3000, Y: 500, Width: 300, Height: 200},
+			want:   backend.Rect{X: 3000, Y: 500, Width: 300, Height: 200},
+		},
+		{
+			name:    "monitor removed",
+			bounds:  backend.Rect{X: 6000, Y: 500, Width: 300, Height: 200},
+			monitor: "gone",
+			want:    backend.Rect{X: 1620, Y: 500, Width: 300, Height: 200},
+		},
+		{
+			name:    "larger than work area",
+			bounds:  backend.Rect{X: -50, Y: -50, Width: 2000, Height: 1200},
+			monitor: "gone",
+			want:    backend.Rect{X: 0, Y: 0, Width: 1920, Height: 1040},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fitToMonitors(tt.bounds, tt.monitor, testMonitors); got != tt.want {
+				t.Errorf("fitToMonitors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
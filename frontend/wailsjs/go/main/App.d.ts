@@ -1,27 +1,89 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
+import {backend} from '../models';
+import {capture} from '../models';
+
+export function DeleteProfile(arg1:string):Promise<void>;
+
+export function DisableAutostart():Promise<void>;
+
+export function DiscoverVehicles(arg1:string,arg2:string):Promise<Array<main.VehicleSummary>>;
+
+export function EnableAutostart(arg1:string):Promise<void>;
+
+export function ExportBackup(arg1:string):Promise<string>;
+
+export function GetAutostart():Promise<main.AutostartStatus>;
+
+export function GetBreakerStatus():Promise<main.BreakerStatus>;
+
+export function GetCachedVehicles():Promise<Array<main.VehicleData>>;
 
 export function GetConfig():Promise<main.Config>;
 
+export function GetDesktopCapabilities():Promise<backend.Capabilities>;
+
+export function GetDesktopState():Promise<backend.DesktopState>;
+
+export function GetMonitors():Promise<Array<backend.Monitor>>;
+
+export function GetOverrides():Promise<Array<string>>;
+
+export function GetProfiles():Promise<Array<string>>;
+
+export function GetSchedulerStatus():Promise<main.SchedulerStatus>;
+
+export function GetSecretsStatus():Promise<main.SecretsStatus>;
+
+export function GetTokenStatus():Promise<Array<main.TokenStatus>>;
+
 export function GetVehicleData():Promise<main.VehicleData>;
 
 export function Greet(arg1:string):Promise<string>;
 
+export function ImportBackup(arg1:string):Promise<main.BackupResult>;
+
+export function ImportCaptureFile():Promise<capture.Session>;
+
+export function IsClickThroughPaused():Promise<boolean>;
+
 export function MinimizeToTray():Promise<void>;
 
 export function MoveToCorner(arg1:string):Promise<void>;
 
 export function Quit():Promise<void>;
 
+export function RefreshNow(arg1:string):Promise<boolean>;
+
 export function ScheduleRefresh():Promise<void>;
 
+export function SetActiveProfile(arg1:string):Promise<void>;
+
+export function SetClickThrough(arg1:boolean):Promise<void>;
+
+export function SetClickThroughPaused(arg1:boolean):Promise<void>;
+
+export function SetOpacity(arg1:number):Promise<void>;
+
+export function SetWindowConfig(arg1:main.WindowConfig):Promise<void>;
+
 export function SetWindowPosition(arg1:number,arg2:number):Promise<void>;
 
 export function ShowWindow():Promise<void>;
 
+export function StartTokenCapture(arg1:string,arg2:number):Promise<main.CaptureInfo>;
+
 export function StartWidget():Promise<void>;
 
-export function ValidateAndSaveConfig(arg1:string,arg2:string,arg3:number):Promise<void>;
+export function StopTokenCapture():Promise<void>;
+
+export function StopWidget():Promise<void>;
+
+export function ToggleWidget():Promise<boolean>;
+
+export function UnlockSecrets(arg1:string):Promise<void>;
+
+export function ValidateAndSaveConfig(arg1:string,arg2:string,arg3:string,arg4:number,arg5:string,arg6:Array<string>):Promise<void>;
 
 export function VehicleHomePage():Promise<any>;
@@ -2,10 +2,74 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function DeleteProfile(arg1) {
+  return window['go']['main']['App']['DeleteProfile'](arg1);
+}
+
+export function DisableAutostart() {
+  return window['go']['main']['App']['DisableAutostart']();
+}
+
+export function DiscoverVehicles(arg1, arg2) {
+  return window['go']['main']['App']['DiscoverVehicles'](arg1, arg2);
+}
+
+export function EnableAutostart(arg1) {
+  return window['go']['main']['App']['EnableAutostart'](arg1);
+}
+
+export function ExportBackup(arg1) {
+  return window['go']['main']['App']['ExportBackup'](arg1);
+}
+
+export function GetAutostart() {
+  return window['go']['main']['App']['GetAutostart']();
+}
+
+export function GetBreakerStatus() {
+  return window['go']['main']['App']['GetBreakerStatus']();
+}
+
+export function GetCachedVehicles() {
+  return window['go']['main']['App']['GetCachedVehicles']();
+}
+
 export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
 
+export function GetDesktopCapabilities() {
+  return window['go']['main']['App']['GetDesktopCapabilities']();
+}
+
+export function GetDesktopState() {
+  return window['go']['main']['App']['GetDesktopState']();
+}
+
+export function GetMonitors() {
+  return window['go']['main']['App']['GetMonitors']();
+}
+
+export function GetOverrides() {
+  return window['go']['main']['App']['GetOverrides']();
+}
+
+export function GetProfiles() {
+  return window['go']['main']['App']['GetProfiles']();
+}
+
+export function GetSchedulerStatus() {
+  return window['go']['main']['App']['GetSchedulerStatus']();
+}
+
+export function GetSecretsStatus() {
+  return window['go']['main']['App']['GetSecretsStatus']();
+}
+
+export function GetTokenStatus() {
+  return window['go']['main']['App']['GetTokenStatus']();
+}
+
 export function GetVehicleData() {
   return window['go']['main']['App']['GetVehicleData']();
 }
@@ -14,6 +78,18 @@ export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ImportBackup(arg1) {
+  return window['go']['main']['App']['ImportBackup'](arg1);
+}
+
+export function ImportCaptureFile() {
+  return window['go']['main']['App']['ImportCaptureFile']();
+}
+
+export function IsClickThroughPaused() {
+  return window['go']['main']['App']['IsClickThroughPaused']();
+}
+
 export function MinimizeToTray() {
   return window['go']['main']['App']['MinimizeToTray']();
 }
@@ -26,10 +102,34 @@ export function Quit() {
   return window['go']['main']['App']['Quit']();
 }
 
+export function RefreshNow(arg1) {
+  return window['go']['main']['App']['RefreshNow'](arg1);
+}
+
 export function ScheduleRefresh() {
   return window['go']['main']['App']['ScheduleRefresh']();
 }
 
+export function SetActiveProfile(arg1) {
+  return window['go']['main']['App']['SetActiveProfile'](arg1);
+}
+
+export function SetClickThrough(arg1) {
+  return window['go']['main']['App']['SetClickThrough'](arg1);
+}
+
+export function SetClickThroughPaused(arg1) {
+  return window['go']['main']['App']['SetClickThroughPaused'](arg1);
+}
+
+export function SetOpacity(arg1) {
+  return window['go']['main']['App']['SetOpacity'](arg1);
+}
+
+export function SetWindowConfig(arg1) {
+  return window['go']['main']['App']['SetWindowConfig'](arg1);
+}
+
 export function SetWindowPosition(arg1, arg2) {
   return window['go']['main']['App']['SetWindowPosition'](arg1, arg2);
 }
@@ -38,12 +138,32 @@ export function ShowWindow() {
   return window['go']['main']['App']['ShowWindow']();
 }
 
+export function StartTokenCapture(arg1, arg2) {
+  return window['go']['main']['App']['StartTokenCapture'](arg1, arg2);
+}
+
 export function StartWidget() {
   return window['go']['main']['App']['StartWidget']();
 }
 
-export function ValidateAndSaveConfig(arg1, arg2, arg3) {
-  return window['go']['main']['App']['ValidateAndSaveConfig'](arg1, arg2, arg3);
+export function StopTokenCapture() {
+  return window['go']['main']['App']['StopTokenCapture']();
+}
+
+export function StopWidget() {
+  return window['go']['main']['App']['StopWidget']();
+}
+
+export function ToggleWidget() {
+  return window['go']['main']['App']['ToggleWidget']();
+}
+
+export function UnlockSecrets(arg1) {
+  return window['go']['main']['App']['UnlockSecrets'](arg1);
+}
+
+export function ValidateAndSaveConfig(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['ValidateAndSaveConfig'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
 export function VehicleHomePage() {
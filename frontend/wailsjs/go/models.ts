@@ -1,9 +1,360 @@
+export namespace backend {
+	
+	export class Capabilities {
+	    platform: string;
+	    widgetMode: boolean;
+	    exitWidgetMode: boolean;
+	    opacity: boolean;
+	    clickThrough: boolean;
+	    alwaysOnBottom: boolean;
+	    placement: boolean;
+	    hotkey: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Capabilities(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.platform = source["platform"];
+	        this.widgetMode = source["widgetMode"];
+	        this.exitWidgetMode = source["exitWidgetMode"];
+	        this.opacity = source["opacity"];
+	        this.clickThrough = source["clickThrough"];
+	        this.alwaysOnBottom = source["alwaysOnBottom"];
+	        this.placement = source["placement"];
+	        this.hotkey = source["hotkey"];
+	    }
+	}
+	export class DesktopState {
+	    widget: boolean;
+	    opacity: number;
+	    clickThrough: boolean;
+	    alwaysOnBottom: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DesktopState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.widget = source["widget"];
+	        this.opacity = source["opacity"];
+	        this.clickThrough = source["clickThrough"];
+	        this.alwaysOnBottom = source["alwaysOnBottom"];
+	    }
+	}
+	export class Rect {
+	    x: number;
+	    y: number;
+	    width: number;
+	    height: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Rect(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	    }
+	}
+	export class Monitor {
+	    id: string;
+	    primary: boolean;
+	    current: boolean;
+	    bounds: Rect;
+	    workArea: Rect;
+	    scale: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Monitor(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.primary = source["primary"];
+	        this.current = source["current"];
+	        this.bounds = this.convertValues(source["bounds"], Rect);
+	        this.workArea = this.convertValues(source["workArea"], Rect);
+	        this.scale = source["scale"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace capture {
+	
+	export class Session {
+	    token: string;
+	    cookie: string;
+	    vehicleIds: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Session(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.token = source["token"];
+	        this.cookie = source["cookie"];
+	        this.vehicleIds = source["vehicleIds"];
+	    }
+	}
+
+}
+
 export namespace main {
 	
+	export class Anchor {
+	    x: number;
+	    y: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Anchor(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.x = source["x"];
+	        this.y = source["y"];
+	    }
+	}
+	export class AutostartStatus {
+	    enabled: boolean;
+	    mode: string;
+	    location: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutostartStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.mode = source["mode"];
+	        this.location = source["location"];
+	    }
+	}
+	export class BackupResult {
+	    profiles: number;
+	    tokensRestored: boolean;
+	    cacheRestored: boolean;
+	    missingTokens: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.profiles = source["profiles"];
+	        this.tokensRestored = source["tokensRestored"];
+	        this.cacheRestored = source["cacheRestored"];
+	        this.missingTokens = source["missingTokens"];
+	    }
+	}
+	export class BreakerStatus {
+	    state: string;
+	    failures: number;
+	    // Go type: time
+	    openUntil: any;
+	    lastError: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BreakerStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.state = source["state"];
+	        this.failures = source["failures"];
+	        this.openUntil = this.convertValues(source["openUntil"], null);
+	        this.lastError = source["lastError"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CaptureInfo {
+	    addresses: string[];
+	    port: number;
+	    certUrls: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CaptureInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.addresses = source["addresses"];
+	        this.port = source["port"];
+	        this.certUrls = source["certUrls"];
+	    }
+	}
+	export class WindowState {
+	    bounds: backend.Rect;
+	    monitor?: string;
+	    widget?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new WindowState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bounds = this.convertValues(source["bounds"], backend.Rect);
+	        this.monitor = source["monitor"];
+	        this.widget = source["widget"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Margins {
+	    top: number;
+	    right: number;
+	    bottom: number;
+	    left: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Margins(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.top = source["top"];
+	        this.right = source["right"];
+	        this.bottom = source["bottom"];
+	        this.left = source["left"];
+	    }
+	}
+	export class WindowConfig {
+	    monitor?: string;
+	    margins?: Margins;
+	    anchor?: Anchor;
+	    layer?: string;
+	    opacity?: number;
+	    clickThrough?: boolean;
+	    hotkey?: string;
+	    state?: WindowState;
+	
+	    static createFrom(source: any = {}) {
+	        return new WindowConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.monitor = source["monitor"];
+	        this.margins = this.convertValues(source["margins"], Margins);
+	        this.anchor = this.convertValues(source["anchor"], Anchor);
+	        this.layer = source["layer"];
+	        this.opacity = source["opacity"];
+	        this.clickThrough = source["clickThrough"];
+	        this.hotkey = source["hotkey"];
+	        this.state = this.convertValues(source["state"], WindowState);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Profile {
+	    name: string;
+	    token?: string;
+	    tokenRef?: string;
+	    vehicleId?: string;
+	    cookie?: string;
+	    selectedVehicles?: string[];
+	    tokenMissing?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Profile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.token = source["token"];
+	        this.tokenRef = source["tokenRef"];
+	        this.vehicleId = source["vehicleId"];
+	        this.cookie = source["cookie"];
+	        this.selectedVehicles = source["selectedVehicles"];
+	        this.tokenMissing = source["tokenMissing"];
+	    }
+	}
 	export class Config {
-	    token: string;
-	    vehicleId: string;
+	    version: number;
+	    profiles: Profile[];
+	    activeProfile: string;
 	    updateInterval: number;
+	    window: WindowConfig;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -11,10 +362,30 @@ export namespace main {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.token = source["token"];
-	        this.vehicleId = source["vehicleId"];
+	        this.version = source["version"];
+	        this.profiles = this.convertValues(source["profiles"], Profile);
+	        this.activeProfile = source["activeProfile"];
 	        this.updateInterval = source["updateInterval"];
+	        this.window = this.convertValues(source["window"], WindowConfig);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class EncryptInfo {
 	    key: string;
@@ -76,6 +447,153 @@ export namespace main {
 	        this.address = source["address"];
 	    }
 	}
+	
+	
+	export class VehicleStatus {
+	    vinNo: string;
+	    vehicleName: string;
+	    // Go type: time
+	    lastRun: any;
+	    // Go type: time
+	    lastSuccess: any;
+	    lastError: string;
+	    // Go type: time
+	    nextRun: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new VehicleStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.vinNo = source["vinNo"];
+	        this.vehicleName = source["vehicleName"];
+	        this.lastRun = this.convertValues(source["lastRun"], null);
+	        this.lastSuccess = this.convertValues(source["lastSuccess"], null);
+	        this.lastError = source["lastError"];
+	        this.nextRun = this.convertValues(source["nextRun"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SchedulerStatus {
+	    running: boolean;
+	    pausedProfiles: string[];
+	    updateInterval: number;
+	    // Go type: time
+	    lastRun: any;
+	    // Go type: time
+	    lastSuccess: any;
+	    lastError: string;
+	    // Go type: time
+	    nextRun: any;
+	    vehicles: VehicleStatus[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SchedulerStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.running = source["running"];
+	        this.pausedProfiles = source["pausedProfiles"];
+	        this.updateInterval = source["updateInterval"];
+	        this.lastRun = this.convertValues(source["lastRun"], null);
+	        this.lastSuccess = this.convertValues(source["lastSuccess"], null);
+	        this.lastError = source["lastError"];
+	        this.nextRun = this.convertValues(source["nextRun"], null);
+	        this.vehicles = this.convertValues(source["vehicles"], VehicleStatus);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SecretsStatus {
+	    backend: string;
+	    locked: boolean;
+	    plaintext: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SecretsStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.backend = source["backend"];
+	        this.locked = source["locked"];
+	        this.plaintext = source["plaintext"];
+	    }
+	}
+	export class TokenStatus {
+	    profile: string;
+	    known: boolean;
+	    // Go type: time
+	    expiresAt: any;
+	    daysRemaining: number;
+	    expired: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TokenStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.profile = source["profile"];
+	        this.known = source["known"];
+	        this.expiresAt = this.convertValues(source["expiresAt"], null);
+	        this.daysRemaining = source["daysRemaining"];
+	        this.expired = source["expired"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class VehicleData {
 	    vinNo: string;
 	    deviceName: string;
@@ -151,6 +669,8 @@ export namespace main {
 	    vehicleScalePicUrl: string;
 	    gaodeLincenseVinNo: string;
 	    gaodeLincenseId: string;
+	    staleSince?: string;
+	    profile?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new VehicleData(source);
@@ -232,6 +752,8 @@ export namespace main {
 	        this.vehicleScalePicUrl = source["vehicleScalePicUrl"];
 	        this.gaodeLincenseVinNo = source["gaodeLincenseVinNo"];
 	        this.gaodeLincenseId = source["gaodeLincenseId"];
+	        this.staleSince = source["staleSince"];
+	        this.profile = source["profile"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -252,6 +774,28 @@ export namespace main {
 		    return a;
 		}
 	}
+	
+	export class VehicleSummary {
+	    vinNo: string;
+	    vehicleName: string;
+	    vehicleType: string;
+	    vehicleTypeName: string;
+	    vehiclePicUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new VehicleSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.vinNo = source["vinNo"];
+	        this.vehicleName = source["vehicleName"];
+	        this.vehicleType = source["vehicleType"];
+	        this.vehicleTypeName = source["vehicleTypeName"];
+	        this.vehiclePicUrl = source["vehiclePicUrl"];
+	    }
+	}
+	
 
 }
 
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 令牌桶：最多连续 5 次请求，之后每 6 秒补充一次
+const (
+	rateLimitBurst    = 5
+	rateLimitInterval = 6 * time.Second
+)
+
+// 熔断器：连续失败 5 次后熔断，冷却结束后放行一次试探请求
+const (
+	breakerFailureThreshold = 5
+	breakerCooldown         = 2 * time.Minute
+	breakerMaxCooldown      = 30 * time.Minute
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// BreakerStatus 熔断器状态，会通过 breakerState 事件推送给前端
+type BreakerStatus struct {
+	State     string    `json:"state"`
+	Failures  int       `json:"failures"`
+	OpenUntil time.Time `json:"openUntil"`
+	LastError string    `json:"lastError"`
+}
+
+// apiGuard 包装对 Zeeho 接口的所有请求，负责全局限流和熔断
+type apiGuard struct {
+	mu sync.Mutex
+
+	// 令牌桶
+	tokens     float64
+	lastRefill time.Time
+
+	// 熔断器
+	state     string
+	failures  int
+	cooldown  time.Duration
+	openUntil time.Time
+	lastError string
+	probing   bool
+
+	onChange func(BreakerStatus)
+}
+
+func newAPIGuard() *apiGuard {
+	return &apiGuard{
+		tokens:     rateLimitBurst,
+		lastRefill: time.Now(),
+		state:      BreakerClosed,
+		cooldown:   breakerCooldown,
+	}
+}
+
+// Do 在限流和熔断的保护下发送请求
+func (g *apiGuard) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if err := g.acquire(time.Now()); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	switch {
+	case err != nil:
+		g.failure(err.Error(), 0)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// 被限流时直接熔断，冷却时间优先使用 Retry-After
+		g.trip("API请求过于频繁 (429)", retryAfter(resp))
+	case resp.StatusCode >= 500:
+		g.failure(fmt.Sprintf("API返回错误状态码 %d", resp.StatusCode), 0)
+	default:
+		g.success()
+	}
+
+	return resp, err
+}
+
+// acquire 检查熔断器状态并获取一个令牌
+func (g *apiGuard) acquire(now time.Time) (err error) {
+	g.update(func() {
+		err = g.acquireLocked(now)
+	})
+	return err
+}
+
+func (g *apiGuard) acquireLocked(now time.Time) error {
+	switch g.state {
+	case BreakerOpen:
+		if now.Before(g.openUntil) {
+			return fmt.Errorf("接口暂不可用，将于 %s 后重试: %s", g.openUntil.Format("15:04:05"), g.lastError)
+		}
+		g.state = BreakerHalfOpen
+	case BreakerHalfOpen:
+		// 半开状态下只放行一个试探请求
+		if g.probing {
+			return fmt.Errorf("接口恢复检测中，请稍后再试")
+		}
+	}
+
+	// 补充令牌
+	elapsed := now.Sub(g.lastRefill)
+	g.tokens += float64(elapsed) / float64(rateLimitInterval)
+	if g.tokens > rateLimitBurst {
+		g.tokens = rateLimitBurst
+	}
+	g.lastRefill = now
+
+	if g.tokens < 1 {
+		wait := time.Duration((1 - g.tokens) * float64(rateLimitInterval))
+		return fmt.Errorf("请求过于频繁，请 %d 秒后再试", int(wait.Seconds())+1)
+	}
+	g.tokens--
+
+	if g.state == BreakerHalfOpen {
+		g.probing = true
+	}
+	return nil
+}
+
+func (g *apiGuard) success() {
+	g.update(func() {
+		g.probing = false
+		g.failures = 0
+		g.cooldown = breakerCooldown
+		g.lastError = ""
+		g.state = BreakerClosed
+	})
+}
+
+func (g *apiGuard) failure(reason string, wait time.Duration) {
+	g.update(func() {
+		g.failures++
+		g.lastError = reason
+
+		// 试探请求失败或连续失败次数过多时熔断
+		if g.state == BreakerHalfOpen || g.failures >= breakerFailureThreshold {
+			g.open(wait)
+		}
+	})
+}
+
+func (g *apiGuard) trip(reason string, wait time.Duration) {
+	g.update(func() {
+		g.failures++
+		g.lastError = reason
+		g.open(wait)
+	})
+}
+
+// open 进入熔断状态，重复熔断时冷却时间翻倍，调用方需持有锁
+func (g *apiGuard) open(wait time.Duration) {
+	if g.state == BreakerHalfOpen {
+		g.cooldown *= 2
+		if g.cooldown > breakerMaxCooldown {
+			g.cooldown = breakerMaxCooldown
+		}
+	}
+	if wait < g.cooldown {
+		wait = g.cooldown
+	}
+
+	g.probing = false
+	g.openUntil = time.Now().Add(wait)
+	g.state = BreakerOpen
+}
+
+// update 在锁内修改状态，状态发生变化时在锁外通知订阅者
+func (g *apiGuard) update(fn func()) {
+	g.mu.Lock()
+	before := g.state
+	fn()
+	changed := g.state != before
+	status := g.statusLocked()
+	g.mu.Unlock()
+
+	if changed && g.onChange != nil {
+		g.onChange(status)
+	}
+}
+
+// Status 返回熔断器当前状态
+func (g *apiGuard) Status() BreakerStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.statusLocked()
+}
+
+func (g *apiGuard) statusLocked() BreakerStatus {
+	return BreakerStatus{
+		State:     g.state,
+		Failures:  g.failures,
+		OpenUntil: g.openUntil,
+		LastError: g.lastError,
+	}
+}
+
+// retryAfter 解析 Retry-After 响应头，只支持秒数格式
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
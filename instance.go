@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 启动时可以指定的操作，已有实例在运行时转发给该实例执行
+const (
+	actionShow         = "show"
+	actionRefresh      = "refresh"
+	actionToggleWidget = "toggle-widget"
+)
+
+var instanceActions = map[string]bool{
+	actionShow:         true,
+	actionRefresh:      true,
+	actionToggleWidget: true,
+}
+
+// errInstanceRunning 已有实例持有单实例锁
+var errInstanceRunning = errors.New("程序已经在运行")
+
+// instanceTimeout 转发操作时等待运行中实例响应的时间
+const instanceTimeout = 10 * time.Second
+
+// instanceAddress 单实例锁使用的 Unix socket 地址。Linux 上使用抽象命名空间，进程退出后自动释放；
+// 其它平台使用缓存目录中的 socket 文件，Windows 10 起支持 AF_UNIX
+func instanceAddress() string {
+	if goruntime.GOOS == "linux" {
+		return fmt.Sprintf("@%s-%d", appDirName, os.Getuid())
+	}
+	return filepath.Join(getCacheDir(), "instance.sock")
+}
+
+// listenInstance 获取单实例锁，监听其它实例转发的操作。已有实例在运行时返回 errInstanceRunning
+func listenInstance() (net.Listener, error) {
+	addr := instanceAddress()
+	if !strings.HasPrefix(addr, "@") {
+		if err := os.MkdirAll(filepath.Dir(addr), 0700); err != nil {
+			return nil, fmt.Errorf("创建目录失败: %v", err)
+		}
+	}
+
+	l, err := net.Listen("unix", addr)
+	if err == nil {
+		return l, nil
+	}
+	conn, dialErr := net.DialTimeout("unix", addr, instanceTimeout)
+	if dialErr == nil {
+		conn.Close()
+		return nil, errInstanceRunning
+	}
+	if strings.HasPrefix(addr, "@") {
+		return nil, fmt.Errorf("监听 %s 失败: %v", addr, err)
+	}
+
+	// 上次异常退出留下的 socket 文件，没有进程在监听
+	if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("删除 %s 失败: %v", addr, err)
+	}
+	l, err = net.Listen("unix", addr)
+	if err != nil {
+		return nil, fmt.Errorf("监听 %s 失败: %v", addr, err)
+	}
+	return l, nil
+}
+
+// sendToInstance 将操作转发给正在运行的实例并等待执行结果
+func sendToInstance(action string) error {
+	conn, err := net.DialTimeout("unix", instanceAddress(), instanceTimeout)
+	if err != nil {
+		return fmt.Errorf("连接运行中的程序失败: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(instanceTimeout))
+
+	if _, err := fmt.Fprintln(conn, action); err != nil {
+		return fmt.Errorf("发送操作失败: %v", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("读取执行结果失败: %v", err)
+	}
+	reply = strings.TrimSpace(reply)
+	if reply != "ok" {
+		return errors.New(strings.TrimPrefix(reply, "error: "))
+	}
+	return nil
+}
+
+// serveInstance 接收其它实例转发的操作，每个连接一行操作名，执行后回复 ok 或 error: 原因
+func (a *App) serveInstance(l net.Listener) {
+	a.instance = l
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Printf("Accept instance connection failed: %v", err)
+				}
+				return
+			}
+			go a.handleInstanceConn(conn)
+		}
+	}()
+}
+
+func (a *App) handleInstanceConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(instanceTimeout))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		// listenInstance 检查实例是否在运行时连接后直接关闭
+		if !errors.Is(err, io.EOF) || line != "" {
+			log.Printf("Read instance action failed: %v", err)
+		}
+		return
+	}
+	action := strings.TrimSpace(line)
+	log.Printf("Received action from another instance: %s", action)
+
+	reply := "ok"
+	if err := a.runAction(action); err != nil {
+		reply = "error: " + err.Error()
+	}
+	conn.SetWriteDeadline(time.Now().Add(instanceTimeout))
+	fmt.Fprintln(conn, reply)
+}
+
+// runAction 执行启动参数指定的操作
+func (a *App) runAction(action string) error {
+	switch action {
+	case actionShow:
+		a.ShowWindow()
+		runtime.WindowUnminimise(a.ctx)
+		return nil
+	case actionRefresh:
+		_, err := a.RefreshNow("")
+		return err
+	case actionToggleWidget:
+		_, err := a.ToggleWidget()
+		return err
+	default:
+		return fmt.Errorf("不支持的操作: %s", action)
+	}
+}
+
+// stopInstance 释放单实例锁，Close 会删除 net.Listen 创建的 socket 文件
+func (a *App) stopInstance() {
+	if a.instance != nil {
+		a.instance.Close()
+	}
+}
@@ -3,8 +3,12 @@ package main
 import (
 	"context"
 	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
 
-	"github.com/bestk/zeeho-widgets/backend"
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -15,9 +19,61 @@ import (
 var assets embed.FS
 
 func main() {
-	app := NewApp()
+	fs := flag.NewFlagSet("zeeho-widgets", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: zeeho-widgets [参数] [show|refresh|toggle-widget]\n      zeeho-widgets config print [--effective] [参数]\n      zeeho-widgets status [--format text|waybar] [--template 模板]\n\n程序已经在运行时，show、refresh、toggle-widget 转发给运行中的程序执行\n\n命令行参数优先于环境变量，环境变量优先于配置文件:")
+		fs.PrintDefaults()
+	}
+	flags := registerOverrideFlags(fs)
+	start := fs.String("start", "", "启动方式：hidden 隐藏到托盘，widget 直接进入小部件模式，开机自启动时使用")
+	fs.Parse(os.Args[1:])
+	if *start != "" && !startModes[*start] {
+		fmt.Fprintf(os.Stderr, "不支持的启动方式: %s\n", *start)
+		os.Exit(2)
+	}
+
+	overrides, err := flags.overrides(fs, os.Getenv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	err := wails.Run(&options.App{
+	// 没有指定操作时再次启动只显示已经运行的窗口
+	action := actionShow
+	if args := fs.Args(); len(args) > 0 {
+		switch {
+		case args[0] == "config":
+			os.Exit(runConfigCommand(args[1:], overrides, os.Stdout, os.Stderr))
+		case args[0] == "status":
+			os.Exit(runStatusCommand(args[1:], os.Stdout, os.Stderr))
+		case instanceActions[args[0]] && len(args) == 1:
+			action = args[0]
+		default:
+			fs.Usage()
+			os.Exit(2)
+		}
+	}
+
+	instance, err := listenInstance()
+	if errors.Is(err, errInstanceRunning) {
+		// 开机自启动时程序已经在运行，不打扰正在运行的程序
+		if *start != "" {
+			os.Exit(0)
+		}
+		if err := sendToInstance(action); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Printf("Single instance lock failed, continue without it: %v", err)
+	}
+
+	app := NewApp(overrides)
+	app.windowHidden.Store(*start == startHidden)
+
+	err = wails.Run(&options.App{
 		Title:  "Zeeho Widget",
 		Width:  440,
 		Height: 300,
@@ -34,18 +90,37 @@ func main() {
 
 			app.startup(ctx)
 
-			backend.SetTransparentBackground()
+			app.restoreWindowState()
+
+			app.prepareDesktop()
+
+			app.applyStartMode(*start)
 
 			app.ScheduleRefresh()
 
+			app.startTray()
+
+			if instance != nil {
+				app.serveInstance(instance)
+			}
+
+			// 启动时本来就会刷新和显示窗口，只有切换小部件模式需要额外执行
+			if action == actionToggleWidget {
+				if err := app.runAction(action); err != nil {
+					log.Println(err)
+				}
+			}
+
 		},
+		OnBeforeClose: app.beforeClose,
+		OnShutdown:    app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
 		Frameless:         true,
 		AlwaysOnTop:       false,
 		DisableResize:     false,
-		StartHidden:       false,
+		StartHidden:       *start == startHidden,
 		HideWindowOnClose: false,
 		WindowStartState:  options.Normal,
 	})
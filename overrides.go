@@ -0,0 +1,333 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 环境变量前缀，参数 vehicle-id 对应 ZEEHO_VEHICLE_ID
+const envPrefix = "ZEEHO_"
+
+// 输出配置时替换敏感字段
+const redacted = "******"
+
+// overrideSetting 一项可以通过命令行参数或环境变量覆盖的配置
+type overrideSetting struct {
+	name    string // 命令行参数名
+	usage   string
+	account bool // 是否为账号相关的配置
+	// apply 将值写入配置，profile 为覆盖的目标账号，为 nil 的配置项不直接修改配置
+	apply func(config *Config, profile *Profile, value string) error
+}
+
+// overrideSettings 新增的配置项需要在这里登记，才能被环境变量和命令行参数覆盖
+var overrideSettings = []overrideSetting{
+	{name: "config", usage: "配置文件路径"},
+	{name: "profile", usage: "要使用的账号，覆盖 activeProfile，账号相关的参数作用于该账号"},
+	{name: "token", usage: "账号的 Token", account: true, apply: func(c *Config, p *Profile, v string) error {
+		p.Token = v
+		return nil
+	}},
+	{name: "vehicle-id", usage: "账号的车架号", account: true, apply: func(c *Config, p *Profile, v string) error {
+		p.VehicleID = v
+		return nil
+	}},
+	{name: "cookie", usage: "账号请求使用的 acw_tc Cookie", account: true, apply: func(c *Config, p *Profile, v string) error {
+		p.Cookie = v
+		return nil
+	}},
+	{name: "selected-vehicles", usage: "需要展示的车架号，逗号分隔", account: true, apply: func(c *Config, p *Profile, v string) error {
+		p.SelectedVehicles = nil
+		for _, vin := range strings.Split(v, ",") {
+			if vin = strings.TrimSpace(vin); vin != "" {
+				p.SelectedVehicles = append(p.SelectedVehicles, vin)
+			}
+		}
+		return nil
+	}},
+	{name: "monitor", usage: "摆放窗口的显示器", apply: func(c *Config, p *Profile, v string) error {
+		c.Window.Monitor = v
+		return nil
+	}},
+	{name: "margins", usage: "窗口与屏幕边缘的距离，一个值作用于四边，或按 上,右,下,左 逗号分隔", apply: func(c *Config, p *Profile, v string) error {
+		margins, err := parseMargins(v)
+		if err != nil {
+			return err
+		}
+		c.Window.Margins = &margins
+		return nil
+	}},
+	{name: "anchor", usage: "自定义窗口位置，位置名称（例如 top-right）或 x,y（0-1）", apply: func(c *Config, p *Profile, v string) error {
+		anchor, err := parseAnchor(v)
+		if err != nil {
+			return err
+		}
+		c.Window.Anchor = &anchor
+		return nil
+	}},
+	{name: "layer", usage: "Wayland 下小部件所在的层：background 或 bottom", apply: func(c *Config, p *Profile, v string) error {
+		c.Window.Layer = v
+		return nil
+	}},
+	{name: "opacity", usage: "窗口不透明度（0.2-1）", apply: func(c *Config, p *Profile, v string) error {
+		opacity, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("不透明度必须是数字: %s", v)
+		}
+		c.Window.Opacity = opacity
+		return nil
+	}},
+	{name: "click-through", usage: "鼠标事件穿透窗口（true / false）", apply: func(c *Config, p *Profile, v string) error {
+		clickThrough, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("必须是 true 或 false: %s", v)
+		}
+		c.Window.ClickThrough = clickThrough
+		return nil
+	}},
+	{name: "hotkey", usage: "临时关闭和恢复鼠标穿透的全局快捷键", apply: func(c *Config, p *Profile, v string) error {
+		c.Window.Hotkey = v
+		return nil
+	}},
+	{name: "update-interval", usage: "数据更新间隔（分钟）", apply: func(c *Config, p *Profile, v string) error {
+		interval, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("更新间隔必须是整数: %s", v)
+		}
+		c.UpdateInterval = interval
+		return nil
+	}},
+}
+
+// parseMargins 解析窗口边距，一个值作用于四边，四个值依次为上、右、下、左
+func parseMargins(v string) (Margins, error) {
+	parts := strings.Split(v, ",")
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return Margins{}, fmt.Errorf("边距必须是整数: %s", v)
+		}
+		values[i] = n
+	}
+	switch len(values) {
+	case 1:
+		return Margins{values[0], values[0], values[0], values[0]}, nil
+	case 4:
+		return Margins{values[0], values[1], values[2], values[3]}, nil
+	}
+	return Margins{}, fmt.Errorf("边距需要一个或四个值: %s", v)
+}
+
+// parseAnchor 解析位置名称或 x,y 坐标
+func parseAnchor(v string) (Anchor, error) {
+	if anchor, ok := anchors[v]; ok {
+		return anchor, nil
+	}
+	parts := strings.Split(v, ",")
+	if len(parts) != 2 {
+		return Anchor{}, fmt.Errorf("未知的位置: %s", v)
+	}
+	x, errX := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if errX != nil || errY != nil {
+		return Anchor{}, fmt.Errorf("位置坐标必须是数字: %s", v)
+	}
+	return Anchor{X: x, Y: y}, nil
+}
+
+// envName 配置项对应的环境变量名
+func (s overrideSetting) envName() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(s.name, "-", "_"))
+}
+
+// configOverrides 通过命令行参数和环境变量指定的配置，只在本次运行中生效，不会写入配置文件。
+// 优先级：命令行参数 > 环境变量 > 配置文件
+type configOverrides struct {
+	values map[string]string
+}
+
+// overrideFlags 在 FlagSet 上注册全部覆盖参数
+type overrideFlags map[string]*string
+
+func registerOverrideFlags(fs *flag.FlagSet) overrideFlags {
+	flags := make(overrideFlags, len(overrideSettings))
+	for _, s := range overrideSettings {
+		flags[s.name] = fs.String(s.name, "", fmt.Sprintf("%s（环境变量 %s）", s.usage, s.envName()))
+	}
+	return flags
+}
+
+// overrides 合并命令行参数和环境变量，只有显式设置的参数才会覆盖环境变量
+func (f overrideFlags) overrides(fs *flag.FlagSet, getenv func(string) string) (configOverrides, error) {
+	o := configOverrides{values: make(map[string]string)}
+	for _, s := range overrideSettings {
+		if v := getenv(s.envName()); v != "" {
+			o.values[s.name] = v
+		}
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		if v, ok := f[fl.Name]; ok {
+			o.values[fl.Name] = *v
+		}
+	})
+
+	if path := o.values["config"]; path != "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return configOverrides{}, fmt.Errorf("config: %v", err)
+		}
+		o.values["config"] = abs
+	}
+
+	// 提前检查取值，避免运行中才发现参数错误
+	if err := o.apply(&Config{}); err != nil {
+		return configOverrides{}, err
+	}
+	return o, nil
+}
+
+// apply 将覆盖的值写入配置。账号相关的配置作用于 profile 指定的账号，
+// 未指定时作用于当前账号或第一个账号，账号不存在时创建
+func (o configOverrides) apply(config *Config) error {
+	name := o.values["profile"]
+	if name != "" {
+		config.ActiveProfile = name
+	} else if config.ActiveProfile != "" {
+		name = config.ActiveProfile
+	} else if len(config.Profiles) > 0 {
+		name = config.Profiles[0].Name
+	} else {
+		name = defaultProfileName
+	}
+
+	profile, exists := config.profile(name)
+	profile.Name = name
+	changed := false
+	for _, s := range overrideSettings {
+		v, ok := o.values[s.name]
+		if !ok || s.apply == nil {
+			continue
+		}
+		if err := s.apply(config, &profile, v); err != nil {
+			return fmt.Errorf("%s: %v", s.name, err)
+		}
+		changed = changed || s.account
+	}
+
+	if exists || changed {
+		config.upsertProfile(profile)
+	}
+	return nil
+}
+
+// names 被覆盖的配置项名称，按 overrideSettings 的顺序
+func (o configOverrides) names() []string {
+	names := []string{}
+	for _, s := range overrideSettings {
+		if _, ok := o.values[s.name]; ok {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+// configPath 覆盖的配置文件路径
+func (o configOverrides) configPath() string {
+	return o.values["config"]
+}
+
+// redact 隐藏配置中的 Token 和 Cookie
+func redact(config Config) Config {
+	config = config.clone()
+	for i := range config.Profiles {
+		p := &config.Profiles[i]
+		if p.Token != "" {
+			p.Token = redacted
+		}
+		if p.Cookie != "" {
+			p.Cookie = redacted
+		}
+	}
+	return config
+}
+
+// effectiveConfig 返回叠加了命令行参数和环境变量之后实际生效的配置。
+// 修改配置时应读取 a.store.Config()，避免把覆盖的值保存到配置文件
+func (a *App) effectiveConfig() Config {
+	config := a.store.Config()
+	// 取值在启动时已经检查过
+	_ = a.overrides.apply(&config)
+	return config
+}
+
+// GetOverrides 返回本次运行中被命令行参数或环境变量覆盖的配置项名称。
+// GetConfig 返回的是配置文件中的值，配置界面据此提示哪些设置暂不生效
+func (a *App) GetOverrides() []string {
+	return a.overrides.names()
+}
+
+// runConfigCommand 执行 config 子命令，返回进程退出码
+func runConfigCommand(args []string, base configOverrides, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(stderr, "用法: zeeho-widgets config print [--effective] [参数]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	effective := fs.Bool("effective", false, "输出叠加命令行参数和环境变量之后实际生效的配置")
+	flags := registerOverrideFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	overrides, err := flags.overrides(fs, func(string) string { return "" })
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	// 子命令后的参数优先于之前的参数和环境变量
+	for k, v := range base.values {
+		if _, ok := overrides.values[k]; !ok {
+			overrides.values[k] = v
+		}
+	}
+
+	path := overrides.configPath()
+	if path == "" {
+		path = filepath.Join(getConfigDir(), "config.json")
+	}
+
+	var config Config
+	data, err := os.ReadFile(path)
+	if err == nil {
+		config, _, err = parseConfig(data)
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if *effective {
+		_ = overrides.apply(&config)
+		if err := config.validate(); err != nil {
+			fmt.Fprintln(stderr, err)
+		}
+	}
+
+	out, err := json.MarshalIndent(redact(config), "", "  ")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintln(stdout, string(out))
+	return 0
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/bestk/zeeho-widgets/secrets"
+	"github.com/zalando/go-keyring"
+)
+
+// 窗口设置可以通过环境变量和命令行参数覆盖，命令行参数优先
+func TestWindowOverrides(t *testing.T) {
+	env := map[string]string{
+		"ZEEHO_MARGINS":       "8",
+		"ZEEHO_ANCHOR":        "top-right",
+		"ZEEHO_OPACITY":       "0.5",
+		"ZEEHO_CLICK_THROUGH": "true",
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerOverrideFlags(fs)
+	if err := fs.Parse([]string{"--margins", "1,2,3,4", "--anchor", "0.25,0.75", "--layer", "background", "--hotkey", "Ctrl+Alt+Z"}); err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := flags.overrides(fs, func(name string) string { return env[name] })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := Config{}
+	if err := overrides.apply(&config); err != nil {
+		t.Fatal(err)
+	}
+	want := WindowConfig{
+		Margins:      &Margins{1, 2, 3, 4},
+		Anchor:       &Anchor{0.25, 0.75},
+		Layer:        "background",
+		Opacity:      0.5,
+		ClickThrough: true,
+		Hotkey:       "Ctrl+Alt+Z",
+	}
+	if !reflect.DeepEqual(config.Window, want) {
+		t.Fatalf("window = %+v, want %+v", config.Window, want)
+	}
+}
+
+func TestInvalidWindowOverrides(t *testing.T) {
+	for name, value := range map[string]string{
+		"ZEEHO_MARGINS":       "1,2",
+		"ZEEHO_ANCHOR":        "somewhere",
+		"ZEEHO_OPACITY":       "half",
+		"ZEEHO_CLICK_THROUGH": "maybe",
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags := registerOverrideFlags(fs)
+		getenv := func(n string) string {
+			if n == name {
+				return value
+			}
+			return ""
+		}
+		if _, err := flags.overrides(fs, getenv); err == nil {
+			t.Errorf("%s=%s should be rejected", name, value)
+		}
+	}
+}
+
+// 配置界面拿到的是配置文件中的值，保存后覆盖的值不会写入配置文件
+func TestOverridesAreNotSavedFromConfigModal(t *testing.T) {
+	keyring.MockInit()
+	app := newTestApp(t, &fakeAPI{})
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := registerOverrideFlags(fs)
+	env := map[string]string{"ZEEHO_TOKEN": "env-token", "ZEEHO_OPACITY": "0.5"}
+	overrides, err := flags.overrides(fs, func(name string) string { return env[name] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.overrides = overrides
+
+	config := testConfig(1, "1")
+	if err := app.saveConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+	if got := app.effectiveConfig().Profiles[0].Token; got != "env-token" {
+		t.Fatalf("effective token = %q", got)
+	}
+	if got := app.GetOverrides(); !reflect.DeepEqual(got, []string{"token", "opacity"}) {
+		t.Fatalf("GetOverrides = %v", got)
+	}
+
+	shown := app.GetConfig()
+	if p := shown.Profiles[0]; p.Token != "1" || shown.Window.Opacity == 0.5 {
+		t.Fatalf("GetConfig should not contain overridden values: %+v", shown)
+	}
+	if err := app.ValidateAndSaveConfig("p1", shown.Profiles[0].Token, "VIN1", 5, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := app.secrets.Load(secrets.RefKeyring, "p1"); err != nil || token != "1" {
+		t.Fatalf("saved token = %q, %v", token, err)
+	}
+	if saved := readConfigFile(t, app); saved.Window.Opacity == 0.5 {
+		t.Fatalf("overridden opacity saved: %+v", saved.Window)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/bestk/zeeho-widgets/backend"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 默认的窗口与屏幕边缘的距离（逻辑像素）
+const defaultWindowMargin = 20
+
+// Anchor 窗口在屏幕可用区域中的相对位置，0 为左/上边缘，1 为右/下边缘，
+// 窗口自身按相同的比例对齐，例如 (1, 1) 表示窗口右下角贴住屏幕右下角
+type Anchor struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Margins 窗口与屏幕边缘的距离（逻辑像素，会按显示器缩放比例换算）
+type Margins struct {
+	Top    int `json:"top"`
+	Right  int `json:"right"`
+	Bottom int `json:"bottom"`
+	Left   int `json:"left"`
+}
+
+// WindowConfig 窗口摆放设置
+type WindowConfig struct {
+	Monitor string   `json:"monitor,omitempty"` // 摆放窗口的显示器，为空时使用窗口当前所在的显示器
+	Margins *Margins `json:"margins,omitempty"` // 为空时四边均为 defaultWindowMargin
+	Anchor  *Anchor  `json:"anchor,omitempty"`  // MoveToCorner("custom") 使用的位置，也是 Wayland 下小部件的位置
+	// Layer Wayland 下小部件所在的层：background（桌面背景层）或 bottom（默认，普通窗口下方）
+	Layer string `json:"layer,omitempty"`
+	// Opacity 窗口不透明度，0.2-1，为 0 时完全不透明
+	Opacity float64 `json:"opacity,omitempty"`
+	// ClickThrough 鼠标事件穿透窗口，直接操作下方的桌面
+	ClickThrough bool `json:"clickThrough,omitempty"`
+	// Hotkey 临时关闭和恢复鼠标穿透的全局快捷键，为空时使用 defaultHotkey
+	Hotkey string `json:"hotkey,omitempty"`
+	// State 上次退出时的窗口状态，由程序自动维护
+	State *WindowState `json:"state,omitempty"`
+}
+
+// anchors MoveToCorner 支持的位置
+var anchors = map[string]Anchor{
+	"top-left":      {0, 0},
+	"top-center":    {0.5, 0},
+	"top-right":     {1, 0},
+	"center-left":   {0, 0.5},
+	"center":        {0.5, 0.5},
+	"center-right":  {1, 0.5},
+	"bottom-left":   {0, 1},
+	"bottom-center": {0.5, 1},
+	"bottom-right":  {1, 1},
+}
+
+// margins 返回窗口边距，未设置时使用默认值
+func (w WindowConfig) margins() Margins {
+	if w.Margins == nil {
+		return Margins{defaultWindowMargin, defaultWindowMargin, defaultWindowMargin, defaultWindowMargin}
+	}
+	return *w.Margins
+}
+
+// anchor 解析位置名称，custom 使用配置中的自定义位置
+func (w WindowConfig) anchor(name string) (Anchor, error) {
+	if name == "custom" {
+		if w.Anchor == nil {
+			return Anchor{}, fmt.Errorf("未设置自定义位置")
+		}
+		return *w.Anchor, nil
+	}
+	if name == "" {
+		// 默认右上角
+		name = "top-right"
+	}
+	anchor, ok := anchors[name]
+	if !ok {
+		return Anchor{}, fmt.Errorf("未知的位置: %s", name)
+	}
+	return anchor, nil
+}
+
+// widgetOptions 小部件模式的选项。Wayland 下程序不能自己设置窗口位置，
+// 由混成器按锚点和边距摆放，未设置自定义位置时为右上角
+func (w WindowConfig) widgetOptions() backend.WidgetOptions {
+	anchor := anchors["top-right"]
+	if w.Anchor != nil {
+		anchor = *w.Anchor
+	}
+	m := w.margins()
+	return backend.WidgetOptions{
+		Layer:   w.Layer,
+		AnchorX: anchor.X,
+		AnchorY: anchor.Y,
+		Margins: backend.Insets{Top: m.Top, Right: m.Right, Bottom: m.Bottom, Left: m.Left},
+	}
+}
+
+// place 计算窗口左上角的位置。area 为显示器可用区域，width、height 为窗口大小，
+// margins 为逻辑像素，按 scale 换算成与 area 相同的单位
+func place(area backend.Rect, width, height int, margins Margins, scale float64, anchor Anchor) (int, int) {
+	if scale <= 0 {
+		scale = 1
+	}
+	left := int(math.Round(float64(margins.Left) * scale))
+	top := int(math.Round(float64(margins.Top) * scale))
+	right := int(math.Round(float64(margins.Right) * scale))
+	bottom := int(math.Round(float64(margins.Bottom) * scale))
+
+	freeX := area.Width - left - right - width
+	freeY := area.Height - top - bottom - height
+
+	x := area.X + left + int(math.Round(float64(freeX)*anchor.X))
+	y := area.Y + top + int(math.Round(float64(freeY)*anchor.Y))
+	return x, y
+}
+
+// pickMonitor 选择摆放窗口的显示器：优先配置的显示器，其次窗口当前所在的显示器，最后是主显示器
+func pickMonitor(monitors []backend.Monitor, id string) (backend.Monitor, bool) {
+	if len(monitors) == 0 {
+		return backend.Monitor{}, false
+	}
+	if id != "" {
+		for _, m := range monitors {
+			if m.ID == id {
+				return m, true
+			}
+		}
+	}
+	for _, m := range monitors {
+		if m.Current {
+			return m, true
+		}
+	}
+	for _, m := range monitors {
+		if m.Primary {
+			return m, true
+		}
+	}
+	return monitors[0], true
+}
+
+// GetMonitors 获取全部显示器，用于选择摆放窗口的显示器
+func (a *App) GetMonitors() ([]backend.Monitor, error) {
+	monitors, err := a.desktop.Monitors()
+	if !errors.Is(err, backend.ErrUnsupported) {
+		return monitors, err
+	}
+
+	// 平台没有实现时使用 Wails 提供的屏幕信息，只有大小没有位置
+	screens, err := runtime.ScreenGetAll(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	monitors = make([]backend.Monitor, 0, len(screens))
+	for i, s := range screens {
+		scale := 1.0
+		if s.Size.Width > 0 && s.PhysicalSize.Width > 0 {
+			scale = float64(s.PhysicalSize.Width) / float64(s.Size.Width)
+		}
+		area := backend.Rect{Width: s.Size.Width, Height: s.Size.Height}
+		monitors = append(monitors, backend.Monitor{
+			ID:       fmt.Sprintf("screen-%d", i),
+			Primary:  s.IsPrimary,
+			Current:  s.IsCurrent,
+			Bounds:   area,
+			WorkArea: area,
+			Scale:    scale,
+		})
+	}
+	return monitors, nil
+}
+
+// MoveToCorner 移动窗口到指定位置：四个角（top-left 等）、四条边的中点（top-center 等）、
+// center，或配置中的自定义位置 custom。按窗口实际大小、显示器缩放比例和配置的边距计算
+func (a *App) MoveToCorner(corner string) error {
+	window := a.effectiveConfig().Window
+	anchor, err := window.anchor(corner)
+	if err != nil {
+		return err
+	}
+
+	bounds, err := a.desktop.WindowBounds()
+	if err == nil {
+		monitors, err := a.desktop.Monitors()
+		if err != nil {
+			return err
+		}
+		monitor, ok := pickMonitor(monitors, window.Monitor)
+		if !ok {
+			return fmt.Errorf("找不到显示器")
+		}
+		x, y := place(monitor.WorkArea, bounds.Width, bounds.Height, window.margins(), monitor.Scale, anchor)
+		if err := a.desktop.SetWindowPosition(x, y); err != nil {
+			return err
+		}
+		a.saveWindowState()
+		return nil
+	}
+	if !errors.Is(err, backend.ErrUnsupported) {
+		return err
+	}
+
+	// 平台没有实现时只能在窗口当前所在的屏幕上移动，不支持选择显示器（忽略 window.monitor），
+	// 坐标和边距都是逻辑像素，不需要按缩放比例换算
+	screens, err := a.GetMonitors()
+	if err != nil {
+		return err
+	}
+	monitor, ok := pickMonitor(screens, "")
+	if !ok {
+		return fmt.Errorf("找不到显示器")
+	}
+	width, height := runtime.WindowGetSize(a.ctx)
+	x, y := place(monitor.WorkArea, width, height, window.margins(), 1, anchor)
+	runtime.WindowSetPosition(a.ctx, x, y)
+	a.saveWindowState()
+	return nil
+}
+
+// SetWindowConfig 保存窗口摆放设置
+func (a *App) SetWindowConfig(window WindowConfig) error {
+	config := a.store.Config()
+	// 窗口状态由程序维护，不使用前端传入的值
+	window.State = config.Window.State
+	config.Window = window
+	if err := config.validate(); err != nil {
+		return err
+	}
+	if err := a.saveConfig(&config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	return nil
+}
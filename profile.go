@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 旧版本单账号配置迁移后的账号名
+const defaultProfileName = "default"
+
+// Profile 一个极核账号及其要展示的车辆
+type Profile struct {
+	Name             string   `json:"name"`
+	Token            string   `json:"token,omitempty"`
+	TokenRef         string   `json:"tokenRef,omitempty"` // Token 的保存位置（keyring / file），配置文件中不保存明文
+	VehicleID        string   `json:"vehicleId,omitempty"`
+	Cookie           string   `json:"cookie,omitempty"`
+	SelectedVehicles []string `json:"selectedVehicles,omitempty"` // 需要展示的车架号，为空时展示全部车辆
+	// TokenMissing 从备份导入后本机没有该账号的 Token，重新填写前不刷新该账号
+	TokenMissing bool `json:"tokenMissing,omitempty"`
+}
+
+// legacyConfig 旧版本的单账号配置
+type legacyConfig struct {
+	Token            string   `json:"token"`
+	TokenRef         string   `json:"tokenRef"`
+	VehicleID        string   `json:"vehicleId"`
+	Cookie           string   `json:"cookie"`
+	SelectedVehicles []string `json:"selectedVehicles"`
+}
+
+// profile 返回一个已配置的账号
+func (l legacyConfig) profile() (Profile, bool) {
+	if l.Token == "" && l.TokenRef == "" {
+		return Profile{}, false
+	}
+	return Profile{
+		Name:             defaultProfileName,
+		Token:            l.Token,
+		TokenRef:         l.TokenRef,
+		VehicleID:        l.VehicleID,
+		Cookie:           l.Cookie,
+		SelectedVehicles: l.SelectedVehicles,
+	}, true
+}
+
+// cookieHeader 返回请求使用的 Cookie 头
+func (p Profile) cookieHeader() string {
+	if p.Cookie != "" {
+		return "acw_tc=" + p.Cookie
+	}
+	return "acw_tc=" + defaultAcwTC
+}
+
+// filterVehicles 按账号配置筛选需要展示的车辆，未选择时返回全部
+func (p Profile) filterVehicles(vehicles []VehicleData) []VehicleData {
+	if len(p.SelectedVehicles) == 0 {
+		return vehicles
+	}
+
+	selected := make(map[string]bool, len(p.SelectedVehicles))
+	for _, vin := range p.SelectedVehicles {
+		selected[vin] = true
+	}
+
+	filtered := make([]VehicleData, 0, len(p.SelectedVehicles))
+	for _, v := range vehicles {
+		if selected[v.VinNo] {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// clone 深拷贝配置，避免多个 goroutine 共享切片
+func (c Config) clone() Config {
+	profiles := make([]Profile, len(c.Profiles))
+	for i, p := range c.Profiles {
+		p.SelectedVehicles = append([]string(nil), p.SelectedVehicles...)
+		profiles[i] = p
+	}
+	c.Profiles = profiles
+	if c.Window.Margins != nil {
+		margins := *c.Window.Margins
+		c.Window.Margins = &margins
+	}
+	if c.Window.Anchor != nil {
+		anchor := *c.Window.Anchor
+		c.Window.Anchor = &anchor
+	}
+	if c.Window.State != nil {
+		state := *c.Window.State
+		c.Window.State = &state
+	}
+	return c
+}
+
+// refreshSettings 影响定时刷新的配置
+type refreshSettings struct {
+	Profiles       []Profile
+	ActiveProfile  string
+	UpdateInterval int
+}
+
+func (c Config) refreshSettings() refreshSettings {
+	c = c.clone()
+	return refreshSettings{
+		Profiles:       c.Profiles,
+		ActiveProfile:  c.ActiveProfile,
+		UpdateInterval: c.UpdateInterval,
+	}
+}
+
+// profile 按名称查找账号
+func (c Config) profile(name string) (Profile, bool) {
+	for _, p := range c.Profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// upsertProfile 添加或替换同名账号
+func (c *Config) upsertProfile(profile Profile) {
+	for i, p := range c.Profiles {
+		if p.Name == profile.Name {
+			c.Profiles[i] = profile
+			return
+		}
+	}
+	c.Profiles = append(c.Profiles, profile)
+}
+
+// removeProfile 删除账号，返回是否存在
+func (c *Config) removeProfile(name string) bool {
+	for i, p := range c.Profiles {
+		if p.Name == name {
+			c.Profiles = append(c.Profiles[:i], c.Profiles[i+1:]...)
+			if c.ActiveProfile == name {
+				c.ActiveProfile = ""
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// activeProfiles 返回需要刷新的账号，未指定当前账号时合并全部账号
+func (c Config) activeProfiles() []Profile {
+	var profiles []Profile
+	for _, p := range c.Profiles {
+		if c.ActiveProfile != "" && p.Name != c.ActiveProfile {
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles
+}
+
+// normalizeProfileName 校验账号名称，为空时使用默认名称
+func normalizeProfileName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return defaultProfileName, nil
+	}
+	if len(name) > 32 {
+		return "", fmt.Errorf("账号名称不能超过32个字符")
+	}
+	return name, nil
+}
+
+// GetProfiles 获取全部账号名称
+func (a *App) GetProfiles() []string {
+	config := a.effectiveConfig()
+	names := make([]string, 0, len(config.Profiles))
+	for _, p := range config.Profiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// SetActiveProfile 切换当前展示的账号，name 为空时合并展示全部账号
+func (a *App) SetActiveProfile(name string) error {
+	config := a.store.Config()
+	if name != "" {
+		if _, ok := config.profile(name); !ok {
+			return fmt.Errorf("账号不存在: %s", name)
+		}
+	}
+
+	config.ActiveProfile = name
+	if err := config.validate(); err != nil {
+		return err
+	}
+	if err := a.saveConfig(&config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+	return nil
+}
+
+// DeleteProfile 删除账号及其保存的 Token
+func (a *App) DeleteProfile(name string) error {
+	config := a.store.Config()
+	profile, ok := config.profile(name)
+	if !ok {
+		return fmt.Errorf("账号不存在: %s", name)
+	}
+
+	config.removeProfile(name)
+	if err := a.saveConfig(&config); err != nil {
+		return fmt.Errorf("保存配置失败: %v", err)
+	}
+
+	if profile.TokenRef != "" {
+		if err := a.secrets.Delete(profile.TokenRef, profile.Name); err != nil {
+			return fmt.Errorf("删除Token失败: %v", err)
+		}
+	}
+	return nil
+}
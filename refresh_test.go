@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bestk/zeeho-widgets/backend"
+	"github.com/bestk/zeeho-widgets/secrets"
+	"github.com/go-co-op/gocron"
+)
+
+// fakeAPI 代替 Zeeho 接口，fail 为 true 时返回服务器错误
+type fakeAPI struct {
+	requests  atomic.Int32
+	fail      atomic.Bool
+	lastToken atomic.Value // 最近一次请求使用的 Token
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests.Add(1)
+	f.lastToken.Store(r.Header.Get("Authorization")[len("Bearer "):])
+	if f.fail.Load() {
+		http.Error(w, `{"code":"50000","message":"busy"}`, http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(APIResponse{
+		Code:    "10000",
+		Message: "success",
+		Data: []map[string]string{{
+			"vinNo":          "VIN" + r.Header.Get("Authorization")[len("Bearer "):],
+			"vehicleName":    "测试车辆",
+			"bmssoc":         "80",
+			"hmiRidableMile": "90",
+		}},
+	})
+}
+
+// newTestApp 创建使用本地接口和临时目录的 App，不读取用户的配置
+func newTestApp(t *testing.T, api http.Handler) *App {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	app := &App{
+		scheduler: gocron.NewScheduler(time.UTC),
+		refresh:   newRefreshState(),
+		store:     NewStore(),
+		guard:     newAPIGuard(),
+		desktop:   backend.NewFake(nil, backend.Rect{}),
+		apiBase:   server.URL,
+	}
+	app.secrets = secrets.New(app.getSecretsPath())
+	app.store.Subscribe(app.onStoreChange)
+	t.Cleanup(app.scheduler.Stop)
+	return app
+}
+
+func testConfig(interval int, tokens ...string) Config {
+	config := Config{Version: configVersion, UpdateInterval: interval}
+	for _, token := range tokens {
+		config.Profiles = append(config.Profiles, Profile{Name: "p" + token, Token: token})
+	}
+	return config
+}
+
+// 配置更新、定时刷新、手动刷新和读取数据同时进行，需要在 go test -race 下通过
+func TestConcurrentConfigAndRefresh(t *testing.T) {
+	api := &fakeAPI{}
+	app := newTestApp(t, api)
+	app.store.SetConfig(testConfig(1, "1"))
+	app.ScheduleRefresh()
+
+	var wg sync.WaitGroup
+	run := func(n int, fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				fn(i)
+			}
+		}()
+	}
+	// 修改间隔会通过 onStoreChange 重新安排定时任务
+	run(20, func(i int) { app.store.SetConfig(testConfig(1+i%2, "1", "2")) })
+	run(20, func(int) { app.refreshVehicles() })
+	run(50, func(int) { _ = app.store.Vehicles() })
+	run(50, func(int) { _ = app.GetSchedulerStatus() })
+	var manual atomic.Int32
+	run(5, func(int) {
+		if ran, err := app.RefreshNow(""); err == nil && ran {
+			manual.Add(1)
+		}
+	})
+	wg.Wait()
+
+	// 防抖间隔内的手动刷新只执行一次
+	if n := manual.Load(); n != 1 {
+		t.Fatalf("manual refresh ran %d times, want 1", n)
+	}
+	if api.requests.Load() == 0 {
+		t.Fatal("API was never called")
+	}
+	vehicles := app.store.Vehicles()
+	if len(vehicles) == 0 || len(vehicles) > 2 {
+		t.Fatalf("got %d vehicles, want 1-2: %+v", len(vehicles), vehicles)
+	}
+	for _, v := range vehicles {
+		if v.Profile == "" || v.VinNo != "VIN"+v.Profile[1:] {
+			t.Errorf("vehicle %s has profile %q", v.VinNo, v.Profile)
+		}
+	}
+	if app.currentRefreshJob() == nil {
+		t.Fatal("refresh job is not scheduled")
+	}
+	if _, err := os.Stat(cachePath()); err != nil {
+		t.Fatalf("cache not written: %v", err)
+	}
+}
+
+// 订阅者在存储锁之外被调用，可以在回调中读取和修改状态
+func TestStoreSubscribersRunOutsideLock(t *testing.T) {
+	store := NewStore()
+	var changes []StoreChange
+	var mu sync.Mutex
+	store.Subscribe(func(change StoreChange) {
+		_ = store.Config()
+		_ = store.Vehicles()
+		mu.Lock()
+		changes = append(changes, change)
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			store.SetConfig(testConfig(1, "1"))
+		}()
+		go func() {
+			defer wg.Done()
+			store.SetVehicles([]VehicleData{{VinNo: "VIN1"}})
+		}()
+	}
+	wg.Wait()
+
+	if len(changes) != 20 {
+		t.Fatalf("got %d notifications, want 20", len(changes))
+	}
+}
+
+// 接口不可用时保留上次的数据并标记为过期
+func TestRefreshFailureKeepsStaleData(t *testing.T) {
+	api := &fakeAPI{}
+	app := newTestApp(t, api)
+	app.store.SetConfig(testConfig(1, "1"))
+
+	app.refreshVehicles()
+	if v := app.store.Vehicles(); len(v) != 1 || v[0].StaleSince != "" {
+		t.Fatalf("first refresh: %+v", v)
+	}
+
+	api.fail.Store(true)
+	app.refreshVehicles()
+	v := app.store.Vehicles()
+	if len(v) != 1 || v[0].StaleSince == "" {
+		t.Fatalf("failed refresh should keep stale data: %+v", v)
+	}
+	if status := app.GetSchedulerStatus(); status.LastError == "" {
+		t.Fatal("last error not recorded")
+	}
+}
+
+// waitRequests 等待接口收到 n 次请求，并等待这次刷新的结果写入
+func waitRequests(t *testing.T, app *App, api *fakeAPI, n int32) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for api.requests.Load() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("API called %d times, want %d", api.requests.Load(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 请求在持有 refreshMu 时发出，获取到锁时刷新已经结束
+	app.refreshMu.Lock()
+	app.refreshMu.Unlock()
+}
+
+// 指定车辆时只刷新该车辆所属的账号，未知的车辆被拒绝
+func TestRefreshNowVehicle(t *testing.T) {
+	api := &fakeAPI{}
+	app := newTestApp(t, api)
+	// 安排定时任务时立即执行一次全部账号的刷新
+	app.store.SetConfig(testConfig(60, "1", "2"))
+	waitRequests(t, app, api, 2)
+	if n := len(app.store.Vehicles()); n != 2 {
+		t.Fatalf("got %d vehicles, want 2", n)
+	}
+
+	if _, err := app.RefreshNow("VIN3"); err == nil {
+		t.Fatal("unknown vehicle should be rejected")
+	}
+
+	ran, err := app.RefreshNow("VIN2")
+	if err != nil || !ran {
+		t.Fatalf("RefreshNow = %v, %v", ran, err)
+	}
+	waitRequests(t, app, api, 3)
+	if n := api.requests.Load(); n != 3 {
+		t.Fatalf("API called %d times, want 3", n)
+	}
+	if token := api.lastToken.Load(); token != "2" {
+		t.Fatalf("refreshed profile with token %v, want 2", token)
+	}
+	if n := len(app.store.Vehicles()); n != 2 {
+		t.Fatalf("got %d vehicles after refreshing one profile, want 2", n)
+	}
+}
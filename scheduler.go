@@ -0,0 +1,410 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron"
+)
+
+// 定时刷新任务标签，手动刷新通过该标签复用同一个任务
+const refreshJobTag = "vehicle-refresh"
+
+// 手动刷新的防抖间隔
+const refreshDebounce = 5 * time.Second
+
+// VehicleStatus 单个车辆的刷新状态
+type VehicleStatus struct {
+	VinNo       string    `json:"vinNo"`
+	VehicleName string    `json:"vehicleName"`
+	LastRun     time.Time `json:"lastRun"`
+	LastSuccess time.Time `json:"lastSuccess"`
+	LastError   string    `json:"lastError"`
+	// NextRun 全部车辆由同一个定时任务刷新，与 SchedulerStatus.NextRun 相同
+	NextRun time.Time `json:"nextRun"`
+}
+
+// SchedulerStatus 定时刷新任务的状态
+type SchedulerStatus struct {
+	Running        bool            `json:"running"`
+	PausedProfiles []string        `json:"pausedProfiles"`
+	UpdateInterval int             `json:"updateInterval"`
+	LastRun        time.Time       `json:"lastRun"`
+	LastSuccess    time.Time       `json:"lastSuccess"`
+	LastError      string          `json:"lastError"`
+	NextRun        time.Time       `json:"nextRun"`
+	Vehicles       []VehicleStatus `json:"vehicles"`
+}
+
+// refreshState 记录每次刷新的结果
+type refreshState struct {
+	mu            sync.Mutex
+	lastRun       time.Time
+	lastSuccess   time.Time
+	lastError     string
+	lastManualRun time.Time
+	vehicles      map[string]*VehicleStatus
+
+	// Token 失效后暂停刷新的账号
+	paused map[string]error
+	warned map[string]bool
+}
+
+func newRefreshState() *refreshState {
+	return &refreshState{
+		vehicles: make(map[string]*VehicleStatus),
+		paused:   make(map[string]error),
+		warned:   make(map[string]bool),
+	}
+}
+
+// record 记录一次刷新结果
+func (s *refreshState) record(started time.Time, data *[]VehicleData, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastRun = started
+
+	if err != nil {
+		s.lastError = err.Error()
+		for _, v := range s.vehicles {
+			v.LastRun = started
+			v.LastError = s.lastError
+		}
+		return
+	}
+
+	s.lastSuccess = started
+	s.lastError = ""
+	if data == nil {
+		return
+	}
+	for _, d := range *data {
+		v, ok := s.vehicles[d.VinNo]
+		if !ok {
+			v = &VehicleStatus{VinNo: d.VinNo}
+			s.vehicles[d.VinNo] = v
+		}
+		v.VehicleName = d.VehicleName
+		v.LastRun = started
+		v.LastSuccess = started
+		v.LastError = ""
+	}
+}
+
+// lastSuccessAt 返回最近一次成功刷新的时间
+func (s *refreshState) lastSuccessAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastSuccess
+}
+
+// pause 暂停账号的刷新，返回是否是新的暂停
+func (s *refreshState) pause(profile string, reason error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.paused[profile]; ok {
+		return false
+	}
+	s.paused[profile] = reason
+	return true
+}
+
+// resume 恢复全部账号的刷新
+func (s *refreshState) resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = make(map[string]error)
+}
+
+// isPaused 判断账号是否已暂停刷新
+func (s *refreshState) isPaused(profile string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.paused[profile]
+	return ok
+}
+
+// warnToken 记录已提醒过的 Token，返回是否需要提醒
+func (s *refreshState) warnToken(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.warned[token] {
+		return false
+	}
+	s.warned[token] = true
+	return true
+}
+
+// allowManual 判断手动刷新是否超过防抖间隔
+func (s *refreshState) allowManual(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastManualRun) < refreshDebounce {
+		return false
+	}
+	s.lastManualRun = now
+	return true
+}
+
+// snapshot 生成当前状态的副本
+func (s *refreshState) snapshot(nextRun time.Time) SchedulerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := SchedulerStatus{
+		LastRun:     s.lastRun,
+		LastSuccess: s.lastSuccess,
+		LastError:   s.lastError,
+		NextRun:     nextRun,
+		Vehicles:    make([]VehicleStatus, 0, len(s.vehicles)),
+	}
+	for _, v := range s.vehicles {
+		vs := *v
+		vs.NextRun = nextRun
+		status.Vehicles = append(status.Vehicles, vs)
+	}
+	sort.Slice(status.Vehicles, func(i, j int) bool {
+		return status.Vehicles[i].VinNo < status.Vehicles[j].VinNo
+	})
+	for profile := range s.paused {
+		status.PausedProfiles = append(status.PausedProfiles, profile)
+	}
+	sort.Strings(status.PausedProfiles)
+	return status
+}
+
+// ScheduleRefresh 按配置的间隔重新安排定时刷新任务，可重复调用
+func (a *App) ScheduleRefresh() {
+	a.jobMu.Lock()
+	defer a.jobMu.Unlock()
+
+	// Stop any existing scheduled tasks
+	a.scheduler.Clear()
+	a.refreshJob = nil
+
+	// 配置更新后重新尝试刷新
+	a.refresh.resume()
+
+	config := a.effectiveConfig()
+	a.scheduled = config.refreshSettings()
+	if config.UpdateInterval < 1 {
+		log.Println("UpdateInterval must > 0")
+	}
+
+	log.Println("Schedule refresh based on config interval")
+	// Schedule refresh based on config interval
+	job, err := a.scheduler.Every(config.UpdateInterval).Minutes().Tag(refreshJobTag).SingletonMode().Do(a.refreshVehicles)
+	if err != nil {
+		log.Printf("Schedule refresh failed: %v", err)
+		return
+	}
+	a.refreshJob = job
+
+	// Start the scheduler
+	a.scheduler.StartAsync()
+}
+
+// refreshVehicles 定时刷新任务，拉取全部账号的车辆数据并通知前端
+func (a *App) refreshVehicles() {
+	a.refreshProfile("")
+}
+
+// refreshProfile 拉取车辆数据并通知前端。only 不为空时只刷新该账号，其它账号的车辆保持不变
+func (a *App) refreshProfile(only string) {
+	a.refreshMu.Lock()
+	defer a.refreshMu.Unlock()
+
+	// Token 失效的账号不再请求接口，等待用户重新配置
+	profiles := a.pollableProfiles()
+	if only != "" {
+		profiles = filterProfiles(profiles, only)
+	}
+	if len(profiles) == 0 {
+		return
+	}
+
+	started := time.Now()
+
+	// Refresh vehicle data
+	vehicles, failures := a.fetchProfiles(profiles)
+	for _, profile := range profiles {
+		if errors.Is(failures[profile.Name], errAuthFailed) {
+			a.pauseProfile(profile, failures[profile.Name])
+		}
+	}
+
+	var data *[]VehicleData
+	var err error
+	if len(vehicles) == 0 && len(failures) > 0 {
+		err = joinFailures(failures)
+	} else {
+		a.resolveAddresses(vehicles)
+		data = &vehicles
+		if len(failures) > 0 {
+			// 部分账号刷新失败，仍然展示其它账号的数据
+			a.emit("refreshError", joinFailures(failures).Error())
+		}
+	}
+
+	a.refresh.record(started, data, err)
+	if err == nil && only != "" {
+		merged := mergeProfileVehicles(a.store.Vehicles(), *data, only)
+		data = &merged
+	}
+	if err != nil {
+		// Handle error - could emit event to frontend
+		a.emit("refreshError", err.Error())
+
+		// 接口不可用时继续展示缓存数据
+		if cached := a.store.Vehicles(); len(cached) > 0 {
+			stale := markStale(cached, a.refresh.lastSuccessAt())
+			if only != "" {
+				// 只有刷新失败的账号的车辆标记为过期
+				stale = mergeProfileVehicles(cached, filterVehicles(stale, only), only)
+			}
+			a.store.SetVehicles(stale)
+			a.emit("dataStale", stale)
+		}
+	} else {
+		a.store.SetVehicles(*data)
+		if err := a.saveCache(*data); err != nil {
+			log.Printf("Save cache failed: %v", err)
+		}
+		// Emit success event to frontend
+		a.emit("dataRefreshed", data)
+	}
+
+	a.emit("schedulerStatus", a.GetSchedulerStatus())
+}
+
+// filterProfiles 只保留名称为 name 的账号
+func filterProfiles(profiles []Profile, name string) []Profile {
+	var filtered []Profile
+	for _, p := range profiles {
+		if p.Name == name {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// filterVehicles 只保留属于账号 profile 的车辆
+func filterVehicles(vehicles []VehicleData, profile string) []VehicleData {
+	var filtered []VehicleData
+	for _, v := range vehicles {
+		if v.Profile == profile {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// mergeProfileVehicles 用 fresh 替换 vehicles 中属于账号 profile 的车辆，保持其它账号的顺序
+func mergeProfileVehicles(vehicles, fresh []VehicleData, profile string) []VehicleData {
+	merged := make([]VehicleData, 0, len(vehicles)+len(fresh))
+	inserted := false
+	for _, v := range vehicles {
+		if v.Profile != profile {
+			merged = append(merged, v)
+			continue
+		}
+		if !inserted {
+			merged = append(merged, fresh...)
+			inserted = true
+		}
+	}
+	if !inserted {
+		merged = append(merged, fresh...)
+	}
+	return merged
+}
+
+// vehicleProfile 返回车辆所属的账号，车辆不在当前数据中时返回 false
+func (a *App) vehicleProfile(vin string) (string, bool) {
+	for _, v := range a.store.Vehicles() {
+		if v.VinNo == vin {
+			return v.Profile, true
+		}
+	}
+	return "", false
+}
+
+// currentRefreshJob 返回当前的定时刷新任务，未安排时为 nil
+func (a *App) currentRefreshJob() *gocron.Job {
+	a.jobMu.Lock()
+	defer a.jobMu.Unlock()
+	return a.refreshJob
+}
+
+// GetSchedulerStatus 获取定时刷新任务状态
+func (a *App) GetSchedulerStatus() SchedulerStatus {
+	job := a.currentRefreshJob()
+
+	var nextRun time.Time
+	if job != nil {
+		nextRun = job.NextRun()
+	}
+
+	status := a.refresh.snapshot(nextRun)
+	status.Running = a.scheduler.IsRunning() && job != nil
+	status.UpdateInterval = a.effectiveConfig().UpdateInterval
+	return status
+}
+
+// RefreshNow 立即刷新车辆数据，vin 为空时执行一次定时刷新任务刷新全部账号。
+// 接口按账号返回全部车辆，指定 vin 时刷新该车辆所属的账号。
+// 返回值表示是否真正触发了刷新，防抖间隔内的重复调用会被忽略
+func (a *App) RefreshNow(vin string) (bool, error) {
+	if a.currentRefreshJob() == nil {
+		return false, fmt.Errorf("刷新任务未启动，请先配置Token")
+	}
+
+	var profiles []string
+	if vin != "" {
+		profile, ok := a.vehicleProfile(vin)
+		if !ok {
+			return false, fmt.Errorf("未找到车辆: %s", vin)
+		}
+		profiles = []string{profile}
+	} else {
+		for _, p := range a.effectiveConfig().activeProfiles() {
+			profiles = append(profiles, p.Name)
+		}
+	}
+
+	paused := true
+	for _, name := range profiles {
+		if !a.refresh.isPaused(name) {
+			paused = false
+			break
+		}
+	}
+	if paused {
+		return false, fmt.Errorf("Token已失效，请重新配置Token")
+	}
+
+	// 防抖：短时间内重复点击只执行一次
+	if !a.refresh.allowManual(time.Now()) {
+		return false, nil
+	}
+
+	if vin != "" {
+		// 与定时任务一样在后台执行，结果通过事件通知前端
+		go a.refreshProfile(profiles[0])
+		return true, nil
+	}
+
+	// gocron 创建任务时修改标签没有加锁，与 ScheduleRefresh 重建任务互斥
+	a.jobMu.Lock()
+	err := a.scheduler.RunByTag(refreshJobTag)
+	a.jobMu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/bestk/zeeho-widgets/secrets"
+)
+
+// SecretsStatus 密钥存储状态
+type SecretsStatus struct {
+	// Backend Token 当前的保存位置（keyring / file），未保存时为空
+	Backend string `json:"backend"`
+	// Locked Token 保存在加密文件中，需要输入密码解锁
+	Locked bool `json:"locked"`
+	// Plaintext 系统密钥环不可用，Token 仍以明文保存在配置文件中，需要设置加密密码
+	Plaintext bool `json:"plaintext"`
+}
+
+// 加密文件路径，系统密钥环不可用时使用
+func (a *App) getSecretsPath() string {
+	return filepath.Join(getConfigDir(), "secrets.json")
+}
+
+// loadTokens 读取每个账号的 Token，明文保存的 Token 迁移到密钥环。
+// rewrite 为 true 时即使没有需要迁移的 Token 也重写配置文件
+func (a *App) loadTokens(config *Config, rewrite bool) {
+	for i := range config.Profiles {
+		profile := &config.Profiles[i]
+		if profile.TokenRef != "" {
+			// Token 保存在密钥环或加密文件中
+			a.loadToken(profile)
+		} else if profile.Token != "" {
+			rewrite = true
+		}
+	}
+
+	if rewrite {
+		a.migrateTokens(config)
+	}
+}
+
+// loadToken 根据账号中的引用读取 Token
+func (a *App) loadToken(profile *Profile) {
+	token, err := a.secrets.Load(profile.TokenRef, profile.Name)
+	if err != nil {
+		if !errors.Is(err, secrets.ErrLocked) {
+			log.Printf("Load token for %s failed: %v", profile.Name, err)
+		}
+		return
+	}
+	profile.Token = token
+}
+
+// migrateTokens 将明文保存的 Token 迁移到密钥环，失败时保留明文但收紧文件权限
+func (a *App) migrateTokens(config *Config) {
+	for i := range config.Profiles {
+		profile := &config.Profiles[i]
+		if profile.TokenRef != "" || profile.Token == "" {
+			continue
+		}
+
+		ref, err := a.secrets.Save(profile.Name, profile.Token)
+		if err != nil {
+			log.Printf("Migrate plaintext token for %s failed, keeping it in config: %v", profile.Name, err)
+			continue
+		}
+		profile.TokenRef = ref
+	}
+
+	if err := a.writeConfigFile(config); err != nil {
+		log.Printf("Rewrite config after token migration failed: %v", err)
+	}
+}
+
+// GetSecretsStatus 获取配置文件中 Token 的保存状态，不受命令行和环境变量覆盖的影响
+func (a *App) GetSecretsStatus() SecretsStatus {
+	var status SecretsStatus
+	for _, p := range a.store.Config().Profiles {
+		if status.Backend == "" {
+			status.Backend = p.TokenRef
+		}
+		if p.TokenRef == secrets.RefFile && p.Token == "" {
+			status.Backend = p.TokenRef
+			status.Locked = true
+		}
+		if p.TokenRef == "" && p.Token != "" {
+			status.Plaintext = true
+		}
+	}
+	return status
+}
+
+// UnlockSecrets 设置加密密码。Token 保存在加密文件中时用它解锁，
+// 系统密钥环不可用时之后保存的 Token 也会用它加密
+func (a *App) UnlockSecrets(passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("密码不能为空")
+	}
+	a.secrets.SetPassphrase(passphrase)
+
+	config := a.store.Config()
+	changed := false
+	for i := range config.Profiles {
+		profile := &config.Profiles[i]
+		if profile.TokenRef != secrets.RefFile || profile.Token != "" {
+			continue
+		}
+		token, err := a.secrets.Load(profile.TokenRef, profile.Name)
+		if err != nil {
+			a.secrets.SetPassphrase("")
+			return err
+		}
+		profile.Token = token
+		changed = true
+	}
+
+	// 之前因为没有密码未能迁移的明文 Token
+	for _, p := range config.Profiles {
+		if p.TokenRef == "" && p.Token != "" {
+			a.migrateTokens(&config)
+			changed = true
+			break
+		}
+	}
+
+	if changed {
+		a.store.SetConfig(config)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/bestk/zeeho-widgets/secrets"
+	"github.com/zalando/go-keyring"
+)
+
+// readConfigFile 读取写入磁盘的配置文件
+func readConfigFile(t *testing.T, app *App) Config {
+	t.Helper()
+	data, err := os.ReadFile(app.getConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+// 没有密钥环也没有设置密码时要求设置加密密码，不以明文保存 Token；
+// 设置密码后 Token 保存到加密文件，其它设置仍然可以保存
+func TestSaveConfigWithoutKeyring(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keyring"))
+	t.Cleanup(keyring.MockInit)
+	app := newTestApp(t, &fakeAPI{})
+
+	config := testConfig(1, "1")
+	if err := app.saveConfig(&config); !errors.Is(err, secrets.ErrNoBackend) {
+		t.Fatalf("save config: %v, want ErrNoBackend", err)
+	}
+	if _, err := os.Stat(app.getConfigPath()); !os.IsNotExist(err) {
+		t.Fatalf("config should not be written: %v", err)
+	}
+
+	if err := app.UnlockSecrets("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	config = testConfig(1, "1")
+	if err := app.saveConfig(&config); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	if err := app.SetActiveProfile("p1"); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	saved := readConfigFile(t, app)
+	if p := saved.Profiles[0]; p.Token != "" || p.TokenRef != secrets.RefFile {
+		t.Fatalf("token should be saved to the encrypted file: %+v", p)
+	}
+	if saved.ActiveProfile != "p1" {
+		t.Fatalf("active profile not saved: %q", saved.ActiveProfile)
+	}
+}
+
+// 无法迁移的明文 Token 在状态中标记出来，设置密码后迁移到加密文件
+func TestPlaintextTokenStatus(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keyring"))
+	t.Cleanup(keyring.MockInit)
+	app := newTestApp(t, &fakeAPI{})
+
+	config := testConfig(1, "1")
+	app.migrateTokens(&config)
+	app.store.SetConfig(config)
+	if status := app.GetSecretsStatus(); !status.Plaintext {
+		t.Fatalf("status = %+v, want plaintext", status)
+	}
+
+	if err := app.UnlockSecrets("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	if status := app.GetSecretsStatus(); status.Plaintext || status.Backend != secrets.RefFile {
+		t.Fatalf("status = %+v, want tokens in the encrypted file", status)
+	}
+	if p := readConfigFile(t, app).Profiles[0]; p.Token != "" || p.TokenRef != secrets.RefFile {
+		t.Fatalf("token not migrated: %+v", p)
+	}
+}
+
+// 只有新增或修改的 Token 会写入密钥环
+func TestSaveConfigOnlySavesChangedTokens(t *testing.T) {
+	keyring.MockInit()
+	app := newTestApp(t, &fakeAPI{})
+
+	config := testConfig(1, "1")
+	if err := app.saveConfig(&config); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	// 密钥环不可用后，Token 没有变化的保存不再访问密钥环
+	keyring.MockInitWithError(errors.New("no keyring"))
+	t.Cleanup(keyring.MockInit)
+	if err := app.SetActiveProfile("p1"); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+	if p := readConfigFile(t, app).Profiles[0]; p.Token != "" || p.TokenRef != secrets.RefKeyring {
+		t.Fatalf("unchanged token should stay in keyring: %+v", p)
+	}
+
+	config = app.store.Config()
+	config.Profiles[0].Token = "2"
+	if err := app.saveConfig(&config); !errors.Is(err, secrets.ErrNoBackend) {
+		t.Fatalf("save changed token: %v, want ErrNoBackend", err)
+	}
+	if p := readConfigFile(t, app).Profiles[0]; p.Token != "" || p.TokenRef != secrets.RefKeyring {
+		t.Fatalf("changed token should not be written in plaintext: %+v", p)
+	}
+}
@@ -0,0 +1,275 @@
+// Package secrets 保存 Token 等敏感信息，优先使用系统密钥环
+// （Linux Secret Service、macOS 钥匙串、Windows 凭据管理器），
+// 密钥环不可用时使用密码加密的本地文件
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/bestk/zeeho-widgets/internal/fsutil"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+// 密钥环中的服务名
+const service = "zeeho-widgets"
+
+// 引用类型，保存在配置文件中用来找到对应的密钥
+const (
+	RefKeyring = "keyring"
+	RefFile    = "file"
+)
+
+var (
+	// ErrLocked 密钥保存在加密文件中，但还没有提供密码
+	ErrLocked = errors.New("密钥文件已加密，请输入密码解锁")
+	// ErrNoBackend 系统密钥环不可用，且没有设置加密密码
+	ErrNoBackend = errors.New("系统密钥环不可用，请设置加密密码")
+	// ErrNotFound 密钥不存在
+	ErrNotFound = errors.New("密钥不存在")
+)
+
+// scrypt 参数
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// Store 密钥存储
+type Store struct {
+	path string
+
+	mu         sync.Mutex
+	passphrase string
+}
+
+// encryptedEntry 加密文件中的一条记录
+type encryptedEntry struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// New 创建密钥存储，path 为加密文件的位置
+func New(path string) *Store {
+	return &Store{path: path}
+}
+
+// SetPassphrase 设置加密文件的密码
+func (s *Store) SetPassphrase(passphrase string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passphrase = passphrase
+}
+
+// HasPassphrase 是否已设置加密文件的密码
+func (s *Store) HasPassphrase() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.passphrase != ""
+}
+
+// Save 保存密钥，返回写入配置文件的引用
+func (s *Store) Save(account, secret string) (string, error) {
+	if err := keyring.Set(service, account, secret); err == nil {
+		return RefKeyring, nil
+	}
+
+	if !s.HasPassphrase() {
+		return "", ErrNoBackend
+	}
+	if err := s.saveFile(account, secret); err != nil {
+		return "", err
+	}
+	return RefFile, nil
+}
+
+// Load 根据引用读取密钥
+func (s *Store) Load(ref, account string) (string, error) {
+	switch ref {
+	case RefKeyring:
+		secret, err := keyring.Get(service, account)
+		if errors.Is(err, keyring.ErrNotFound) {
+			return "", ErrNotFound
+		}
+		if err != nil {
+			return "", fmt.Errorf("读取系统密钥环失败: %v", err)
+		}
+		return secret, nil
+	case RefFile:
+		return s.loadFile(account)
+	default:
+		return "", fmt.Errorf("未知的密钥引用: %s", ref)
+	}
+}
+
+// Delete 删除密钥
+func (s *Store) Delete(ref, account string) error {
+	switch ref {
+	case RefKeyring:
+		err := keyring.Delete(service, account)
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil
+		}
+		return err
+	case RefFile:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		entries, err := s.readFile()
+		if err != nil {
+			return err
+		}
+		delete(entries, account)
+		return s.writeFile(entries)
+	default:
+		return nil
+	}
+}
+
+// saveFile 在持有锁的情况下读取、修改并写回加密文件，避免同时保存时丢失记录
+func (s *Store) saveFile(account, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := seal(s.passphrase, []byte(secret), []byte(account))
+	if err != nil {
+		return err
+	}
+
+	entries, err := s.readFile()
+	if err != nil {
+		return err
+	}
+	entries[account] = entry
+	return s.writeFile(entries)
+}
+
+func (s *Store) loadFile(account string) (string, error) {
+	s.mu.Lock()
+	passphrase := s.passphrase
+	s.mu.Unlock()
+
+	if passphrase == "" {
+		return "", ErrLocked
+	}
+
+	entries, err := s.readFile()
+	if err != nil {
+		return "", err
+	}
+	entry, ok := entries[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	plain, err := open(passphrase, entry, []byte(account))
+	if err != nil {
+		return "", fmt.Errorf("密码错误或密钥文件已损坏")
+	}
+	return string(plain), nil
+}
+
+func (s *Store) readFile() (map[string]encryptedEntry, error) {
+	entries := make(map[string]encryptedEntry)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("密钥文件格式错误: %v", err)
+	}
+	return entries, nil
+}
+
+func (s *Store) writeFile(entries map[string]encryptedEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fsutil.WriteFileAtomic(s.path, data, 0600); err != nil {
+		return fmt.Errorf("保存密钥文件失败: %v", err)
+	}
+	return nil
+}
+
+// Seal 使用密码加密数据，aad 为附加的校验数据，解密时需要提供相同的值。
+// 用于导出备份等需要离开本机的场景
+func Seal(passphrase string, plaintext, aad []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("密码不能为空")
+	}
+	entry, err := seal(passphrase, plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(entry)
+}
+
+// Open 解密 Seal 加密的数据
+func Open(passphrase string, sealed, aad []byte) ([]byte, error) {
+	var entry encryptedEntry
+	if err := json.Unmarshal(sealed, &entry); err != nil {
+		return nil, fmt.Errorf("加密数据格式错误: %v", err)
+	}
+	plain, err := open(passphrase, entry, aad)
+	if err != nil {
+		return nil, fmt.Errorf("密码错误或数据已损坏")
+	}
+	return plain, nil
+}
+
+func seal(passphrase string, plaintext, aad []byte) (encryptedEntry, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return encryptedEntry{}, err
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return encryptedEntry{}, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return encryptedEntry{}, err
+	}
+
+	return encryptedEntry{
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, aad),
+	}, nil
+}
+
+func open(passphrase string, entry encryptedEntry, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(passphrase, entry.Salt)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, entry.Nonce, entry.Ciphertext, aad)
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
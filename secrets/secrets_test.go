@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// 没有密钥环时，首次保存会创建加密文件所在的目录
+func TestSaveFileCreatesDirectory(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keyring"))
+	t.Cleanup(keyring.MockInit)
+
+	store := New(filepath.Join(t.TempDir(), "zeeho-widgets", "secrets.json"))
+	if _, err := store.Save("p1", "token"); !errors.Is(err, ErrNoBackend) {
+		t.Fatalf("save without passphrase: %v, want ErrNoBackend", err)
+	}
+
+	store.SetPassphrase("passphrase")
+	ref, err := store.Save("p1", "token")
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if ref != RefFile {
+		t.Fatalf("ref = %q, want %q", ref, RefFile)
+	}
+	if got, err := store.Load(ref, "p1"); err != nil || got != "token" {
+		t.Fatalf("load = %q, %v", got, err)
+	}
+}
+
+// 同时保存多个账号时加密文件中不会丢失记录
+func TestConcurrentSaveFile(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no keyring"))
+	t.Cleanup(keyring.MockInit)
+
+	store := New(filepath.Join(t.TempDir(), "secrets.json"))
+	store.SetPassphrase("passphrase")
+
+	accounts := []string{"p1", "p2", "p3", "p4"}
+	var wg sync.WaitGroup
+	for _, account := range accounts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := store.Save(account, "token-"+account); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, account := range accounts {
+		if got, err := store.Load(RefFile, account); err != nil || got != "token-"+account {
+			t.Errorf("load %s = %q, %v", account, got, err)
+		}
+	}
+}
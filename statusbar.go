@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 状态栏默认显示电量、充电状态和续航
+const defaultStatusTemplate = `{{.BmsSoc}}%{{if .Charging}} ⚡{{end}} {{.HmiRidableMile}}km`
+
+// statusData 状态栏模板使用的数据，VehicleData 的字段可以直接使用，例如 {{.VehicleName}}
+type statusData struct {
+	VehicleData
+	Soc      int    // 电量百分比，无法解析时为 -1
+	Charging bool   // 正在充电
+	Stale    bool   // 运行中的程序没有及时刷新，数据可能已经过期
+	Level    string // 电量等级：critical、low、normal，无法解析时为 unknown
+	// Vehicles 缓存中的全部车辆
+	Vehicles []VehicleData
+}
+
+// waybarOutput waybar 自定义模块的 JSON 输出（return-type 为 json）
+type waybarOutput struct {
+	Text       string   `json:"text"`
+	Tooltip    string   `json:"tooltip"`
+	Class      []string `json:"class"`
+	Percentage int      `json:"percentage"`
+}
+
+// runStatusCommand 执行 status 子命令，从运行中的程序写入的缓存读取车辆数据输出到状态栏，
+// 不请求接口，状态栏可以频繁调用。返回进程退出码
+func runStatusCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "输出格式：text 按模板输出一行文字（polybar、i3blocks、tmux），waybar 输出 waybar 的 JSON")
+	tmpl := fs.String("template", defaultStatusTemplate, "Go text/template 模板，可以使用 VehicleData 的字段以及 .Soc .Charging .Stale .Level")
+	vin := fs.String("vin", "", "要显示的车架号，默认显示第一辆车")
+	profile := fs.String("profile", "", "只显示该账号的车辆")
+	low := fs.Int("low", 20, "电量低于该百分比时等级为 low")
+	critical := fs.Int("critical", 10, "电量低于该百分比时等级为 critical")
+	maxAge := fs.Duration("max-age", time.Hour, "缓存超过该时间没有更新时标记为过期，0 表示不检查")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "text" && *format != "waybar" {
+		fmt.Fprintf(stderr, "不支持的输出格式: %s\n", *format)
+		return 2
+	}
+	t, err := template.New("status").Option("missingkey=error").Parse(*tmpl)
+	if err != nil {
+		fmt.Fprintf(stderr, "解析模板失败: %v\n", err)
+		return 2
+	}
+
+	vehicles, err := readStatusVehicles(*profile, *maxAge)
+	if err == nil && len(vehicles) == 0 {
+		err = errors.New("暂无车辆数据")
+	}
+	var data statusData
+	if err == nil {
+		data, err = selectStatusVehicle(vehicles, *vin, *low, *critical)
+	}
+	if err != nil {
+		// waybar 在命令失败时会隐藏模块，输出不可用状态而不是报错
+		if *format == "waybar" {
+			return writeJSON(stdout, stderr, waybarOutput{Tooltip: html.EscapeString(err.Error()), Class: []string{"unavailable"}})
+		}
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	var text strings.Builder
+	if err := t.Execute(&text, data); err != nil {
+		fmt.Fprintf(stderr, "执行模板失败: %v\n", err)
+		return 1
+	}
+
+	if *format == "text" {
+		fmt.Fprintln(stdout, text.String())
+		return 0
+	}
+	// waybar 按 Pango 标记解析 text 和 tooltip，车辆名称中的 & < 等字符需要转义，
+	// 因此模板中不能使用 Pango 标记
+	lines := make([]string, 0, len(vehicles))
+	for _, v := range vehicles {
+		lines = append(lines, html.EscapeString(vehicleSummary(v)))
+	}
+	out := waybarOutput{
+		Text:       html.EscapeString(text.String()),
+		Tooltip:    strings.Join(lines, "\n"),
+		Class:      []string{data.Level},
+		Percentage: max(data.Soc, 0),
+	}
+	if data.Charging {
+		out.Class = append(out.Class, "charging")
+	}
+	if data.Stale {
+		out.Class = append(out.Class, "stale")
+	}
+	return writeJSON(stdout, stderr, out)
+}
+
+// readStatusVehicles 读取缓存中的车辆，缓存超过 maxAge 没有更新时标记为过期
+func readStatusVehicles(profile string, maxAge time.Duration) ([]VehicleData, error) {
+	data, err := os.ReadFile(cachePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取缓存失败: %v", err)
+	}
+	var cache vehicleCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("解析缓存失败: %v", err)
+	}
+
+	vehicles := cache.Vehicles
+	if maxAge > 0 && time.Since(cache.SavedAt) > maxAge {
+		vehicles = markStale(vehicles, cache.SavedAt)
+	}
+	if profile == "" {
+		return vehicles, nil
+	}
+	var filtered []VehicleData
+	for _, v := range vehicles {
+		if v.Profile == profile {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered, nil
+}
+
+// selectStatusVehicle 选出要显示的车辆并计算电量等级
+func selectStatusVehicle(vehicles []VehicleData, vin string, low, critical int) (statusData, error) {
+	data := statusData{VehicleData: vehicles[0], Vehicles: vehicles}
+	if vin != "" {
+		found := false
+		for _, v := range vehicles {
+			if v.VinNo == vin {
+				data.VehicleData = v
+				found = true
+				break
+			}
+		}
+		if !found {
+			return statusData{}, fmt.Errorf("缓存中没有车辆: %s", vin)
+		}
+	}
+
+	data.Charging = data.ChargeState == "1"
+	data.Stale = data.StaleSince != ""
+	data.Soc = -1
+	data.Level = "unknown"
+	if soc, err := strconv.ParseFloat(strings.TrimSpace(data.BmsSoc), 64); err == nil {
+		data.Soc = int(math.Round(soc))
+		switch {
+		case data.Soc < critical:
+			data.Level = "critical"
+		case data.Soc < low:
+			data.Level = "low"
+		default:
+			data.Level = "normal"
+		}
+	}
+	return data, nil
+}
+
+// vehicleSummary 一辆车的电量、续航和充电状态，用于托盘和状态栏的提示文字
+func vehicleSummary(v VehicleData) string {
+	name := v.VehicleName
+	if name == "" {
+		name = "未命名车辆"
+	}
+	line := fmt.Sprintf("%s  电量 %s%%  续航 %skm", name, v.BmsSoc, v.HmiRidableMile)
+	if v.ChargeState == "1" {
+		line += "  充电中"
+	}
+	if v.StaleSince != "" {
+		line += "（离线数据）"
+	}
+	return line
+}
+
+// writeJSON 输出一行 JSON，提示文字中的 & < > 已按 Pango 标记转义，不再转义为 \u0026
+func writeJSON(stdout, stderr io.Writer, v interface{}) int {
+	enc := json.NewEncoder(stdout)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
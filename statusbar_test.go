@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bestk/zeeho-widgets/internal/fsutil"
+)
+
+// waybar 输出中的车辆名称按 Pango 标记转义
+func TestWaybarOutputIsEscaped(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	data, err := json.Marshal(vehicleCache{
+		SavedAt:  time.Now(),
+		Vehicles: []VehicleData{{VinNo: "VIN1", VehicleName: "R&D <1>", BmsSoc: "80", HmiRidableMile: "120"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fsutil.WriteFileAtomic(cachePath(), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runStatusCommand([]string{"--format", "waybar", "--template", "{{.VehicleName}} {{.Soc}}%"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	var out waybarOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "R&amp;D &lt;1&gt; 80%"; out.Text != want {
+		t.Errorf("text = %q, want %q", out.Text, want)
+	}
+	if want := "R&amp;D &lt;1&gt;"; !strings.Contains(out.Tooltip, want) {
+		t.Errorf("tooltip = %q, want it to contain %q", out.Tooltip, want)
+	}
+
+	// text 格式原样输出
+	stdout.Reset()
+	if code := runStatusCommand([]string{"--template", "{{.VehicleName}}"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != "R&D <1>\n" {
+		t.Errorf("text output = %q", got)
+	}
+}
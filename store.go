@@ -0,0 +1,104 @@
+package main
+
+import (
+	"sync"
+)
+
+// StoreChange 状态变更类型
+type StoreChange int
+
+const (
+	// ConfigChanged 配置已更新
+	ConfigChanged StoreChange = iota
+	// VehiclesChanged 车辆数据已更新
+	VehiclesChanged
+)
+
+// Store 应用状态中心，配置和最近一次的车辆数据都通过它读写，
+// 可以被定时任务、前端调用等多个 goroutine 同时访问
+type Store struct {
+	mu       sync.RWMutex
+	config   Config
+	vehicles []VehicleData
+
+	subMu  sync.Mutex
+	nextID int
+	subs   map[int]func(StoreChange)
+}
+
+// NewStore 创建状态中心
+func NewStore() *Store {
+	return &Store{
+		subs: make(map[int]func(StoreChange)),
+	}
+}
+
+// Config 返回当前配置的副本
+func (s *Store) Config() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config.clone()
+}
+
+// SetConfig 替换当前配置并通知订阅者
+func (s *Store) SetConfig(config Config) {
+	s.mu.Lock()
+	s.config = config.clone()
+	s.mu.Unlock()
+
+	s.notify(ConfigChanged)
+}
+
+// Vehicles 返回最近一次获取到的车辆数据副本
+func (s *Store) Vehicles() []VehicleData {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.vehicles == nil {
+		return nil
+	}
+	vehicles := make([]VehicleData, len(s.vehicles))
+	copy(vehicles, s.vehicles)
+	return vehicles
+}
+
+// SetVehicles 替换车辆数据并通知订阅者
+func (s *Store) SetVehicles(vehicles []VehicleData) {
+	copied := make([]VehicleData, len(vehicles))
+	copy(copied, vehicles)
+
+	s.mu.Lock()
+	s.vehicles = copied
+	s.mu.Unlock()
+
+	s.notify(VehiclesChanged)
+}
+
+// Subscribe 订阅状态变更，返回取消订阅的函数。
+// 回调在修改方的 goroutine 中同步执行，且不持有任何锁
+func (s *Store) Subscribe(fn func(StoreChange)) func() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subs[id] = fn
+
+	return func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+func (s *Store) notify(change StoreChange) {
+	s.subMu.Lock()
+	subs := make([]func(StoreChange), 0, len(s.subs))
+	for _, fn := range s.subs {
+		subs = append(subs, fn)
+	}
+	s.subMu.Unlock()
+
+	for _, fn := range subs {
+		fn(change)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// errAuthFailed Token 无效或已过期
+var errAuthFailed = errors.New("认证失败，请检查Token是否正确")
+
+// Token 剩余天数小于该值时提醒用户
+const tokenExpiringDays = 3
+
+// TokenStatus Token 有效期信息
+type TokenStatus struct {
+	Profile string `json:"profile"`
+	// Known 是否能从 Token 中解析出过期时间
+	Known         bool      `json:"known"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	DaysRemaining int       `json:"daysRemaining"`
+	Expired       bool      `json:"expired"`
+}
+
+// parseTokenExpiry 解析 JWT 格式 Token 的 exp 字段，非 JWT 返回 false
+func parseTokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, false
+	}
+
+	exp, err := claims.Exp.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(exp), 0), true
+}
+
+// tokenStatus 计算 Token 在 now 时刻的有效期信息
+func tokenStatus(token string, now time.Time) TokenStatus {
+	expiresAt, ok := parseTokenExpiry(token)
+	if !ok {
+		return TokenStatus{}
+	}
+
+	remaining := expiresAt.Sub(now)
+	return TokenStatus{
+		Known:         true,
+		ExpiresAt:     expiresAt,
+		DaysRemaining: int(remaining.Hours() / 24),
+		Expired:       remaining <= 0,
+	}
+}
+
+// isAuthFailure 判断响应是否表示认证失败
+func isAuthFailure(resp *http.Response, body []byte) bool {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return true
+	}
+	// 认证失败时接口会返回 HTML 页面
+	return len(body) > 0 && body[0] == '<'
+}
+
+// GetTokenStatus 获取每个账号 Token 的有效期信息
+func (a *App) GetTokenStatus() []TokenStatus {
+	profiles := a.effectiveConfig().Profiles
+	statuses := make([]TokenStatus, 0, len(profiles))
+	for _, p := range profiles {
+		status := tokenStatus(p.Token, time.Now())
+		status.Profile = p.Name
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// checkToken 刷新前检查账号 Token 的有效期，已过期返回 false。
+// 即将过期时每个 Token 只提醒一次
+func (a *App) checkToken(profile Profile) bool {
+	status := tokenStatus(profile.Token, time.Now())
+	status.Profile = profile.Name
+	if !status.Known {
+		return true
+	}
+
+	if status.Expired {
+		a.pauseProfile(profile, errAuthFailed)
+		return false
+	}
+
+	if status.DaysRemaining < tokenExpiringDays && a.refresh.warnToken(profile.Token) {
+		a.emit("tokenExpiring", status)
+	}
+	return true
+}
+
+// pauseProfile Token 失效后暂停该账号的定时刷新，直到配置更新
+func (a *App) pauseProfile(profile Profile, reason error) {
+	if a.refresh.pause(profile.Name, reason) {
+		status := tokenStatus(profile.Token, time.Now())
+		status.Profile = profile.Name
+		status.Expired = true
+		a.emit("tokenExpired", status)
+	}
+}
+
+// pollableProfiles 返回需要刷新的账号，跳过 Token 已失效或等待重新填写的账号
+func (a *App) pollableProfiles() []Profile {
+	var profiles []Profile
+	for _, p := range a.effectiveConfig().activeProfiles() {
+		if p.Token == "" && p.TokenMissing {
+			continue
+		}
+		if a.refresh.isPaused(p.Name) || !a.checkToken(p) {
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bestk/zeeho-widgets/capture"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 抓包代理默认端口
+const defaultCapturePort = 8899
+
+// captureTimeout 抓包代理没有捕获到 Token 时自动停止的时间，避免代理一直对局域网开放
+const captureTimeout = 10 * time.Minute
+
+// CaptureInfo 抓包代理信息，用于在手机上配置代理和下载证书
+type CaptureInfo struct {
+	Addresses []string `json:"addresses"`
+	Port      int      `json:"port"`
+	CertURLs  []string `json:"certUrls"`
+}
+
+// CaptureResult 抓包结果，通过 tokenCaptured 事件推送给前端
+type CaptureResult struct {
+	Saved bool   `json:"saved"`
+	Error string `json:"error"`
+	// Stopped 代理已经停止，保存成功或超时后为 true
+	Stopped bool `json:"stopped"`
+}
+
+// 抓包证书保存目录
+func (a *App) getCaptureDir() string {
+	return filepath.Join(getConfigDir(), "capture")
+}
+
+// StartTokenCapture 启动抓包代理，捕获的 Token 保存到 profile 账号，
+// port 为 0 时使用默认端口
+func (a *App) StartTokenCapture(profile string, port int) (*CaptureInfo, error) {
+	name, err := normalizeProfileName(profile)
+	if err != nil {
+		return nil, err
+	}
+	if port == 0 {
+		port = defaultCapturePort
+	}
+
+	a.captureMu.Lock()
+	defer a.captureMu.Unlock()
+
+	a.captureTo = name
+
+	if a.capture == nil {
+		proxy, err := capture.New(a.getCaptureDir(), a.onTokenCaptured)
+		if err != nil {
+			return nil, fmt.Errorf("初始化抓包代理失败: %v", err)
+		}
+		a.capture = proxy
+	}
+
+	if a.capture.Port() == 0 {
+		if err := a.capture.Start(fmt.Sprintf(":%d", port)); err != nil {
+			return nil, err
+		}
+	}
+	// 每次开始抓包都重新计时，之前的计时器可能已经触发，通过序号忽略
+	if a.captureEnd != nil {
+		a.captureEnd.Stop()
+	}
+	a.captureGen++
+	gen := a.captureGen
+	a.captureEnd = time.AfterFunc(captureTimeout, func() { a.onCaptureTimeout(gen) })
+
+	info := &CaptureInfo{
+		Addresses: capture.LocalAddresses(),
+		Port:      a.capture.Port(),
+	}
+	for _, addr := range info.Addresses {
+		info.CertURLs = append(info.CertURLs, fmt.Sprintf("http://%s:%d/", addr, info.Port))
+	}
+	return info, nil
+}
+
+// StopTokenCapture 停止抓包代理
+func (a *App) StopTokenCapture() {
+	a.captureMu.Lock()
+	defer a.captureMu.Unlock()
+	a.stopCaptureLocked()
+}
+
+// stopCaptureLocked 停止代理和超时计时器，调用方需要持有 captureMu
+func (a *App) stopCaptureLocked() {
+	a.captureGen++
+	if a.captureEnd != nil {
+		a.captureEnd.Stop()
+		a.captureEnd = nil
+	}
+	if a.capture != nil {
+		if err := a.capture.Close(); err != nil {
+			log.Printf("Stop capture proxy failed: %v", err)
+		}
+	}
+}
+
+// onCaptureTimeout 超时后停止抓包代理并通知前端。gen 与当前的抓包序号不同时，
+// 说明抓包已经停止或重新开始，不再处理
+func (a *App) onCaptureTimeout(gen uint64) {
+	a.captureMu.Lock()
+	if gen != a.captureGen {
+		a.captureMu.Unlock()
+		return
+	}
+	a.stopCaptureLocked()
+	a.captureMu.Unlock()
+
+	a.emit("tokenCaptured", CaptureResult{
+		Error:   fmt.Sprintf("%d 分钟内没有捕获到 Token，抓包代理已停止", int(captureTimeout.Minutes())),
+		Stopped: true,
+	})
+}
+
+// ImportCaptureFile 选择 HAR 或 Charles .chlsj 抓包文件，提取 Token、Cookie 和车辆ID，
+// 由前端填入配置表单，用户确认后再保存
+func (a *App) ImportCaptureFile() (*capture.Session, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入抓包文件",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "抓包文件 (*.har, *.chlsj)", Pattern: "*.har;*.chlsj"},
+			{DisplayName: "JSON (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	if path == "" {
+		// 用户取消
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取抓包文件失败: %v", err)
+	}
+
+	return capture.ParseSession(data)
+}
+
+// onTokenCaptured 捕获到 Token 后直接验证并保存配置，成功后停止代理
+func (a *App) onTokenCaptured(token string) {
+	a.captureMu.Lock()
+	name := a.captureTo
+	a.captureMu.Unlock()
+
+	config := a.store.Config()
+	updateInterval := config.UpdateInterval
+	if updateInterval < 1 {
+		updateInterval = 5
+	}
+
+	// 沿用账号原有的车辆设置
+	profile, _ := config.profile(name)
+
+	result := CaptureResult{Saved: true, Stopped: true}
+	if err := a.ValidateAndSaveConfig(name, token, profile.VehicleID, updateInterval, profile.Cookie, profile.SelectedVehicles); err != nil {
+		result = CaptureResult{Error: err.Error()}
+	} else {
+		a.StopTokenCapture()
+	}
+
+	a.emit("tokenCaptured", result)
+}
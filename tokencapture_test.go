@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+// 上一次抓包留下的超时计时器不会停止新开始的抓包
+func TestStaleCaptureTimeoutIsIgnored(t *testing.T) {
+	app := newTestApp(t, &fakeAPI{})
+	app.captureGen = 2
+
+	app.onCaptureTimeout(1)
+	if app.captureGen != 2 {
+		t.Fatal("stale timeout stopped the current capture")
+	}
+
+	app.onCaptureTimeout(2)
+	if app.captureGen == 2 {
+		t.Fatal("current timeout did not stop the capture")
+	}
+}
@@ -0,0 +1,277 @@
+//go:build windows || linux
+
+package main
+
+import (
+	_ "embed"
+	"log"
+	goruntime "runtime"
+	"strings"
+	"sync"
+
+	"fyne.io/systray"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+//go:embed build/appicon.png
+var trayIconPNG []byte
+
+//go:embed build/windows/icon.ico
+var trayIconICO []byte
+
+// trayCorners 托盘菜单中“窗口位置”的选项，名称见 anchors
+var trayCorners = []struct{ name, label string }{
+	{"top-left", "左上角"},
+	{"top-right", "右上角"},
+	{"center", "屏幕中央"},
+	{"bottom-left", "左下角"},
+	{"bottom-right", "右下角"},
+}
+
+// tray 系统托盘图标，Linux 上通过 StatusNotifierItem 实现。
+// 菜单项创建后不再删除，账号列表变化时复用已有的菜单项并隐藏多余的
+type tray struct {
+	app *App
+
+	mu           sync.Mutex
+	ready        bool
+	show         *systray.MenuItem
+	widget       *systray.MenuItem
+	clickThrough *systray.MenuItem
+	profileMenu  *systray.MenuItem
+	profiles     []*systray.MenuItem
+	profileNames []string // 与 profiles 一一对应，第一项为空表示全部账号
+	unsubscribe  func()
+}
+
+// startTray 在单独的线程中运行托盘图标。Windows 上托盘窗口和消息循环必须在同一个线程
+func (a *App) startTray() {
+	t := &tray{app: a}
+	a.tray = t
+	go func() {
+		goruntime.LockOSThread()
+		systray.Run(t.onReady, nil)
+	}()
+}
+
+// stopTray 移除托盘图标
+func (a *App) stopTray() {
+	if a.tray == nil {
+		return
+	}
+	a.tray.mu.Lock()
+	unsubscribe := a.tray.unsubscribe
+	a.tray.mu.Unlock()
+	if unsubscribe != nil {
+		unsubscribe()
+	}
+	systray.Quit()
+}
+
+// updateTray 窗口或鼠标穿透状态变化后更新托盘菜单
+func (a *App) updateTray() {
+	if a.tray != nil {
+		a.tray.update()
+	}
+}
+
+func (t *tray) onReady() {
+	a := t.app
+	if goruntime.GOOS == "windows" {
+		systray.SetIcon(trayIconICO)
+	} else {
+		systray.SetIcon(trayIconPNG)
+	}
+	systray.SetTitle("Zeeho Widget")
+	systray.SetTooltip("Zeeho Widget")
+	// 左键单击托盘图标时显示或隐藏窗口
+	systray.SetOnTapped(t.toggleWindow)
+
+	refresh := systray.AddMenuItem("立即刷新", "立即获取车辆数据")
+	t.handle(refresh, func() {
+		if _, err := a.RefreshNow(""); err != nil {
+			log.Printf("Tray refresh failed: %v", err)
+		}
+	})
+
+	t.mu.Lock()
+	t.show = systray.AddMenuItem("隐藏窗口", "")
+	t.widget = systray.AddMenuItemCheckbox("小部件模式", "将窗口嵌入桌面", false)
+	t.clickThrough = systray.AddMenuItemCheckbox("临时关闭鼠标穿透", "", false)
+	t.mu.Unlock()
+	t.handle(t.show, t.toggleWindow)
+	t.handle(t.widget, func() {
+		if _, err := a.ToggleWidget(); err != nil {
+			a.reportTrayError(err)
+		}
+	})
+	t.handle(t.clickThrough, func() {
+		a.SetClickThroughPaused(!a.IsClickThroughPaused())
+	})
+
+	corners := systray.AddMenuItem("窗口位置", "")
+	for _, corner := range trayCorners {
+		name := corner.name
+		t.handle(corners.AddSubMenuItem(corner.label, ""), func() {
+			if err := a.MoveToCorner(name); err != nil {
+				a.reportTrayError(err)
+			}
+		})
+	}
+
+	t.mu.Lock()
+	t.profileMenu = systray.AddMenuItem("账号", "切换展示的账号")
+	t.mu.Unlock()
+
+	systray.AddSeparator()
+	t.handle(systray.AddMenuItem("退出", "退出 Zeeho Widget"), a.Quit)
+
+	t.mu.Lock()
+	t.ready = true
+	t.unsubscribe = a.store.Subscribe(func(StoreChange) { t.update() })
+	t.mu.Unlock()
+	t.update()
+}
+
+// handle 在单独的 goroutine 中处理菜单项的点击
+func (t *tray) handle(item *systray.MenuItem, fn func()) {
+	go func() {
+		for range item.ClickedCh {
+			fn()
+		}
+	}()
+}
+
+// toggleWindow 显示或隐藏窗口
+func (t *tray) toggleWindow() {
+	if t.app.windowHidden.Load() {
+		t.app.ShowWindow()
+	} else {
+		t.app.MinimizeToTray()
+	}
+}
+
+// update 根据车辆数据、配置和窗口状态更新提示文字和菜单
+func (t *tray) update() {
+	a := t.app
+	config := a.effectiveConfig()
+	vehicles := a.store.Vehicles()
+	state, err := a.desktop.State()
+	if err != nil {
+		log.Printf("Get desktop state failed: %v", err)
+	}
+	caps := a.desktop.Capabilities()
+	paused := a.IsClickThroughPaused()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.ready {
+		return
+	}
+
+	systray.SetTooltip(trayTooltip(vehicles))
+
+	if a.windowHidden.Load() {
+		t.show.SetTitle("显示窗口")
+	} else {
+		t.show.SetTitle("隐藏窗口")
+	}
+
+	// 无法直接退出小部件模式时，勾选项表示下次启动时是否进入小部件模式
+	setVisible(t.widget, caps.WidgetMode)
+	if state.Widget && !caps.ExitWidgetMode {
+		t.widget.SetTitle("小部件模式（重启后退出）")
+		setChecked(t.widget, config.Window.State != nil && config.Window.State.Widget)
+	} else {
+		t.widget.SetTitle("小部件模式")
+		setChecked(t.widget, state.Widget)
+	}
+
+	setVisible(t.clickThrough, config.Window.ClickThrough && caps.ClickThrough)
+	setChecked(t.clickThrough, paused)
+
+	t.updateProfiles(config)
+}
+
+// updateProfiles 同步账号子菜单，只有一个账号时隐藏
+func (t *tray) updateProfiles(config Config) {
+	names := []string{""}
+	for _, p := range config.Profiles {
+		names = append(names, p.Name)
+	}
+	t.profileNames = names
+
+	for len(t.profiles) < len(names) {
+		i := len(t.profiles)
+		item := t.profileMenu.AddSubMenuItemCheckbox("", "", false)
+		t.profiles = append(t.profiles, item)
+		t.handle(item, func() { t.selectProfile(i) })
+	}
+	for i, item := range t.profiles {
+		if i >= len(names) {
+			item.Hide()
+			continue
+		}
+		if names[i] == "" {
+			item.SetTitle("全部账号")
+		} else {
+			item.SetTitle(names[i])
+		}
+		setChecked(item, names[i] == config.ActiveProfile)
+		item.Show()
+	}
+	setVisible(t.profileMenu, len(config.Profiles) > 1)
+}
+
+// selectProfile 切换到账号子菜单中的第 i 项
+func (t *tray) selectProfile(i int) {
+	t.mu.Lock()
+	if i >= len(t.profileNames) {
+		t.mu.Unlock()
+		return
+	}
+	name := t.profileNames[i]
+	t.mu.Unlock()
+
+	if err := t.app.SetActiveProfile(name); err != nil {
+		t.app.reportTrayError(err)
+	}
+	// 切换失败时恢复勾选状态
+	t.update()
+}
+
+// trayTooltip 托盘提示文字，每辆车一行：电量、续航和充电状态
+func trayTooltip(vehicles []VehicleData) string {
+	if len(vehicles) == 0 {
+		return "Zeeho Widget\n暂无车辆数据"
+	}
+	lines := make([]string, 0, len(vehicles))
+	for _, v := range vehicles {
+		lines = append(lines, vehicleSummary(v))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reportTrayError 托盘菜单的操作失败时记录日志并通知前端
+func (a *App) reportTrayError(err error) {
+	log.Printf("Tray action failed: %v", err)
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "desktopError", err.Error())
+	}
+}
+
+func setVisible(item *systray.MenuItem, visible bool) {
+	if visible {
+		item.Show()
+	} else {
+		item.Hide()
+	}
+}
+
+func setChecked(item *systray.MenuItem, checked bool) {
+	if checked {
+		item.Check()
+	} else {
+		item.Uncheck()
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows && !linux
+
+package main
+
+import "log"
+
+// tray macOS 的状态栏图标需要和 Wails 共用 NSApplication，暂不支持
+type tray struct{}
+
+func (a *App) startTray() {
+	log.Println("System tray is not supported on this platform")
+}
+
+func (a *App) stopTray()   {}
+func (a *App) updateTray() {}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+)
+
+// VehicleSummary 账号下绑定的车辆，用于配置时选择展示哪些车辆
+type VehicleSummary struct {
+	VinNo           string `json:"vinNo"`
+	VehicleName     string `json:"vehicleName"`
+	VehicleType     string `json:"vehicleType"`
+	VehicleTypeName string `json:"vehicleTypeName"`
+	VehiclePicUrl   string `json:"vehiclePicUrl"`
+}
+
+// DiscoverVehicles 使用 Token 获取账号下绑定的车辆，同时验证 Token 是否有效
+func (a *App) DiscoverVehicles(token, cookie string) ([]VehicleSummary, error) {
+	if token == "" {
+		return nil, fmt.Errorf("Token不能为空")
+	}
+
+	vehicles, err := a.fetchHomePage(Profile{Token: token, Cookie: cookie})
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]VehicleSummary, 0, len(vehicles))
+	for _, v := range vehicles {
+		summaries = append(summaries, VehicleSummary{
+			VinNo:           v.VinNo,
+			VehicleName:     v.VehicleName,
+			VehicleType:     v.VehicleType,
+			VehicleTypeName: v.VehicleTypeName,
+			VehiclePicUrl:   v.VehiclePicUrl,
+		})
+	}
+	return summaries, nil
+}
+
+// validateVehicles 验证 Token，并检查选择的车辆是否都绑定在账号下
+func (a *App) validateVehicles(config *Profile) error {
+	if config.Token == "" {
+		return fmt.Errorf("Token不能为空")
+	}
+
+	vehicles, err := a.fetchHomePage(*config)
+	if err != nil {
+		return err
+	}
+
+	bound := make(map[string]bool, len(vehicles))
+	for _, v := range vehicles {
+		bound[v.VinNo] = true
+	}
+	for _, vin := range config.SelectedVehicles {
+		if !bound[vin] {
+			return fmt.Errorf("车辆 %s 未绑定在该账号下", vin)
+		}
+	}
+	return nil
+}
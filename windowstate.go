@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bestk/zeeho-widgets/backend"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// WindowState 上次退出时的窗口状态，启动时恢复
+type WindowState struct {
+	// Bounds 窗口位置和大小，Windows 和 X11 上为虚拟桌面的物理像素，其它平台为逻辑像素
+	Bounds backend.Rect `json:"bounds"`
+	// Monitor 窗口所在的显示器，只在 Windows 和 X11 上记录。其它平台没有稳定的显示器标识，
+	// 恢复时窗口出现在启动时所在的显示器上
+	Monitor string `json:"monitor,omitempty"`
+	// Widget 是否处于桌面小部件模式
+	Widget bool `json:"widget,omitempty"`
+}
+
+// currentWindowState 读取窗口当前的位置、大小和所在的显示器
+func (a *App) currentWindowState() (WindowState, error) {
+	var state WindowState
+
+	bounds, err := a.desktop.WindowBounds()
+	if errors.Is(err, backend.ErrUnsupported) {
+		bounds.X, bounds.Y = runtime.WindowGetPosition(a.ctx)
+		bounds.Width, bounds.Height = runtime.WindowGetSize(a.ctx)
+	} else if err != nil {
+		return state, err
+	}
+	state.Bounds = bounds
+
+	// Wails 的屏幕序号在显示器变化后不再对应同一个显示器，不作为显示器标识保存
+	monitors, err := a.desktop.Monitors()
+	if errors.Is(err, backend.ErrUnsupported) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	for _, m := range monitors {
+		if m.Current {
+			state.Monitor = m.ID
+		}
+	}
+	return state, nil
+}
+
+// saveWindowState 保存窗口当前的状态，最小化或尺寸无效时只更新小部件模式
+func (a *App) saveWindowState() {
+	if a.ctx == nil {
+		return
+	}
+
+	config := a.store.Config()
+	state := WindowState{}
+	if config.Window.State != nil {
+		state = *config.Window.State
+	}
+
+	if !runtime.WindowIsMinimised(a.ctx) {
+		current, err := a.currentWindowState()
+		if err != nil {
+			log.Printf("Read window state failed: %v", err)
+		} else if current.Bounds.Width > 0 && current.Bounds.Height > 0 {
+			current.Widget = state.Widget
+			state = current
+		}
+	}
+
+	a.setWindowState(state)
+}
+
+// setWindowState 将窗口状态写入配置
+func (a *App) setWindowState(state WindowState) {
+	config := a.store.Config()
+	if config.Window.State != nil && *config.Window.State == state {
+		return
+	}
+	config.Window.State = &state
+	if err := a.saveConfig(&config); err != nil {
+		log.Printf("Save window state failed: %v", err)
+	}
+}
+
+// setWidgetMode 记录是否处于桌面小部件模式，并通知前端
+func (a *App) setWidgetMode(widget bool) {
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "widgetMode", widget)
+	}
+
+	config := a.store.Config()
+	state := WindowState{}
+	if config.Window.State != nil {
+		state = *config.Window.State
+	}
+	state.Widget = widget
+	a.setWindowState(state)
+}
+
+// restoreWindowState 恢复上次退出时的窗口位置、大小和小部件模式。
+// 原来的显示器已经断开时，将窗口移动到当前显示器的可见范围内
+func (a *App) restoreWindowState() {
+	window := a.effectiveConfig().Window
+	state := window.State
+	if state == nil {
+		return
+	}
+
+	if state.Bounds.Width > 0 && state.Bounds.Height > 0 {
+		bounds := state.Bounds
+		monitors, err := a.GetMonitors()
+		if err != nil {
+			log.Printf("Restore window state failed: %v", err)
+		} else {
+			bounds = fitToMonitors(bounds, state.Monitor, monitors)
+		}
+
+		if err := a.desktop.SetWindowBounds(bounds); errors.Is(err, backend.ErrUnsupported) {
+			runtime.WindowSetSize(a.ctx, bounds.Width, bounds.Height)
+			runtime.WindowSetPosition(a.ctx, bounds.X, bounds.Y)
+		} else if err != nil {
+			log.Printf("Restore window state failed: %v", err)
+		}
+	}
+
+	if state.Widget {
+		if err := a.desktop.EnterWidgetMode(window.widgetOptions()); err != nil {
+			a.reportDesktopError("恢复小部件模式", err)
+		}
+	}
+}
+
+// fitToMonitors 原来的显示器仍然存在且窗口在其范围内时保持原位置，
+// 没有记录显示器时（旧版本保存的状态）窗口在任一显示器范围内即可。
+// 否则将窗口限制在当前显示器（或主显示器）的可用区域内
+func fitToMonitors(bounds backend.Rect, id string, monitors []backend.Monitor) backend.Rect {
+	for _, m := range monitors {
+		if (id == "" || m.ID == id) && intersects(m.Bounds, bounds) {
+			return bounds
+		}
+	}
+
+	monitor, ok := pickMonitor(monitors, "")
+	if !ok {
+		return bounds
+	}
+	area := monitor.WorkArea
+	if bounds.Width > area.Width {
+		bounds.Width = area.Width
+	}
+	if bounds.Height > area.Height {
+		bounds.Height = area.Height
+	}
+	bounds.X = clamp(bounds.X, area.X, area.X+area.Width-bounds.Width)
+	bounds.Y = clamp(bounds.Y, area.Y, area.Y+area.Height-bounds.Height)
+	return bounds
+}
+
+func intersects(a, b backend.Rect) bool {
+	return a.X < b.X+b.Width && b.X < a.X+a.Width &&
+		a.Y < b.Y+b.Height && b.Y < a.Y+a.Height
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}